@@ -0,0 +1,207 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pgruenbacher/got/families"
+	"github.com/pgruenbacher/got/regions"
+)
+
+type AmbushRates struct {
+	// terrain an army can lie hidden in
+	Terrains []regions.Terrain
+}
+
+// the army lies hidden in its region, and surprises any enemy entering it
+type AmbushOrder struct {
+	ArmyOrder
+}
+
+// the army falls upon any enemy entering the adjacent region
+type InterceptOrder struct {
+	ArmyOrder
+	Region regions.RegionId
+}
+
+type StratagemContext string
+
+const (
+	AMBUSH_SET    StratagemContext = "AMBUSH_SET"
+	INTERCEPT_SET StratagemContext = "INTERCEPT_SET"
+)
+
+// stratagems are only disclosed to the house of the army until they are sprung
+type StratagemEvent struct {
+	ArmyEvent
+	Region regions.RegionId
+	Ctx    StratagemContext
+}
+
+func (self ArmiesManager) newStratagemEvent(id armyId, region regions.RegionId, ctx StratagemContext) StratagemEvent {
+	e := StratagemEvent{
+		ArmyEvent: newArmyEvent(id),
+		Region:    region,
+		Ctx:       ctx,
+	}
+	e.Restrict(self.Armies[id].commandingHouses()...)
+	return e
+}
+
+func (self *ArmiesManager) ambushOrders(orders []AmbushOrder) (e []StratagemEvent, err error) {
+	if err = self.validateAmbushOrders(orders); err != nil {
+		return e, err
+	}
+	for _, order := range orders {
+		self.ambushes = append(self.ambushes, order)
+		e = append(e, self.newStratagemEvent(order.ArmyId, self.Armies[order.ArmyId].Region.Id, AMBUSH_SET))
+	}
+	return e, nil
+}
+
+func (self *ArmiesManager) interceptOrders(orders []InterceptOrder) (e []StratagemEvent, err error) {
+	if err = self.validateInterceptOrders(orders); err != nil {
+		return e, err
+	}
+	for _, order := range orders {
+		self.intercepts = append(self.intercepts, order)
+		e = append(e, self.newStratagemEvent(order.ArmyId, order.Region, INTERCEPT_SET))
+	}
+	return e, nil
+}
+
+// HiddenOrders are the ambushes and intercepts of the house, which no other house may see.
+func (self ArmiesManager) HiddenOrders(house families.HouseId) (ambushes []AmbushOrder, intercepts []InterceptOrder) {
+	for _, order := range self.ambushes {
+		if self.CanCommand(house, order.ArmyId) {
+			ambushes = append(ambushes, order)
+		}
+	}
+	for _, order := range self.intercepts {
+		if self.CanCommand(house, order.ArmyId) {
+			intercepts = append(intercepts, order)
+		}
+	}
+	return ambushes, intercepts
+}
+
+// an army lying in wait for the entering army, the ambush is sprung once
+func (self *ArmiesManager) springAmbush(army *Army, present []*Army) *Army {
+	for i, order := range self.ambushes {
+		for _, ambusher := range present {
+			if ambusher.Id == order.ArmyId && self.diplomacy.IsEnemy(army.House, ambusher.House) {
+				self.ambushes = append(self.ambushes[:i], self.ambushes[i+1:]...)
+				return ambusher
+			}
+		}
+	}
+	return nil
+}
+
+// an enemy army watching the region the army just entered, the intercept is sprung once
+func (self *ArmiesManager) springIntercept(army *Army, tmpArmies Armies) *Army {
+	for i, order := range self.intercepts {
+		interceptor, ok := tmpArmies[order.ArmyId]
+		if !ok || order.Region != army.Region.Id || interceptor.inCombat() || !self.diplomacy.IsEnemy(army.House, interceptor.House) {
+			continue
+		}
+		self.intercepts = append(self.intercepts[:i], self.intercepts[i+1:]...)
+		return interceptor
+	}
+	return nil
+}
+
+// an enemy watching the region falls upon the army as it arrives
+func (self *ArmiesManager) intercepted(army *Army, tmpArmies Armies) (b battles, e []MarchEvent) {
+	interceptor := self.springIntercept(army, tmpArmies)
+	if interceptor == nil {
+		return b, e
+	}
+	army.setInCombat()
+	interceptor.setInCombat()
+	e = append(e, newMarchEvent(interceptor.Id, interceptor.Region.Id, army.Region.Id, INTERCEPT))
+	b = append(b, newBattle(interceptor, army, INTERCEPT))
+	return b, e
+}
+
+// an army can't both march and hold to support another or lie in wait
+func marching(orders []MarchOrder, id armyId) bool {
+	for _, order := range orders {
+		if order.ArmyId == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *ArmiesManager) withdrawStratagems(orders []MarchOrder) {
+	ambushes := self.ambushes[:0]
+	for _, ambush := range self.ambushes {
+		if !marching(orders, ambush.ArmyId) {
+			ambushes = append(ambushes, ambush)
+		}
+	}
+	self.ambushes = ambushes
+	intercepts := self.intercepts[:0]
+	for _, intercept := range self.intercepts {
+		if !marching(orders, intercept.ArmyId) {
+			intercepts = append(intercepts, intercept)
+		}
+	}
+	self.intercepts = intercepts
+}
+
+func (self *ArmiesManager) hidden(id armyId) bool {
+	for _, order := range self.ambushes {
+		if order.ArmyId == id {
+			return true
+		}
+	}
+	for _, order := range self.intercepts {
+		if order.ArmyId == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *ArmiesManager) validateAmbushOrders(orders []AmbushOrder) error {
+	for _, order := range orders {
+		army, ok := self.Armies[order.ArmyId]
+		if !ok {
+			return errors.New(fmt.Sprintf("order %v had invalid armyId %v", order.Id, order.ArmyId))
+		}
+		if self.hidden(army.Id) {
+			return errors.New(fmt.Sprintf("army %v already lies in wait", army.Id))
+		}
+		favourable := false
+		for _, terrain := range self.Config.Ambush.Terrains {
+			favourable = favourable || terrain == army.Region.Terrain
+		}
+		if !favourable {
+			return errors.New(fmt.Sprintf("army %v can't hide in %v terrain", army.Id, army.Region.Terrain))
+		}
+	}
+	return nil
+}
+
+func (self *ArmiesManager) validateInterceptOrders(orders []InterceptOrder) error {
+	for _, order := range orders {
+		army, ok := self.Armies[order.ArmyId]
+		if !ok {
+			return errors.New(fmt.Sprintf("order %v had invalid armyId %v", order.Id, order.ArmyId))
+		}
+		if self.hidden(army.Id) {
+			return errors.New(fmt.Sprintf("army %v already lies in wait", army.Id))
+		}
+		edge, ok := army.Region.Edges[order.Region]
+		if !ok {
+			return errors.New(fmt.Sprintf("army %v is not adjacent to region %v", army.Id, order.Region))
+		}
+		// no army can sally across a wall unseen
+		if edge.Boundary.Kind() == regions.WALL {
+			return errors.New(fmt.Sprintf("army %v can't intercept across the wall to %v", army.Id, order.Region))
+		}
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 
 	"gopkg.in/validator.v2"
 
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/diplomats"
 	"github.com/pgruenbacher/got/families"
 	"github.com/pgruenbacher/got/regions"
 )
@@ -31,26 +33,51 @@ type Army struct {
 	DefenseState   defenseStatus
 	StartingRegion regions.RegionId `validate:"nonzero"`
 	HomeRegion     regions.RegionId //May get rid of, want to use house reference
-	Region         *regions.Region
-	Home           *regions.Region
+	Region         *regions.Region  `validate:"-" json:"-"`
+	Home           *regions.Region  `validate:"-" json:"-"`
 	House          families.HouseId `validate:"nonzero"`
+	// liege commanding the army as part of the house's levy obligation
+	LeviedBy  families.HouseId
+	Commander characters.CharacterId `toml:"commander"`
+	commander *characters.Character
+	// the unit types making up the army, empty if the army is of one kind
+	Composition Composition `toml:"composition"`
+	// gained in battle, raises quality over time
+	Experience int `toml:"experience" validate:"min=0"`
+	// how boldly the army fights, balanced if unset
+	Stance Stance `toml:"stance"`
 }
 
+// Strength summarizes the army, whatever it is composed of.
 func (self Army) Strength() int {
 	return self.Morale + self.Size + self.Quality
 }
 
+func (self Armies) OfHouse(houseId families.HouseId) (armies []*Army) {
+	for _, army := range self {
+		if army.House == houseId {
+			armies = append(armies, army)
+		}
+	}
+	return armies
+}
+
 // Using config values to initialize the rest of the object
 func (self Armies) Init(r regions.Regions) error {
 	for armyId, army := range self {
 		// perform struct field validations
 		if err := validator.Validate(army); err != nil {
-			fmt.Println("error!")
 			// values not valid, deal with errors here
 			return err
 		}
 		// set army id using existing key
 		army.Id = armyId
+		if err := army.validateComposition(nil); err != nil {
+			return err
+		}
+		if !army.Stance.valid() {
+			return errors.New(fmt.Sprintf("army %v has invalid stance %v", army.Id, army.Stance))
+		}
 		// declare starting regions
 		if region, ok := r[army.StartingRegion]; ok {
 			army.Region = region
@@ -67,26 +94,38 @@ func (self Armies) Init(r regions.Regions) error {
 	return nil
 }
 
-func (self Armies) EvalSupplies(r regions.Regions) error {
+func (self Armies) EvalSupplies(r regions.Regions, d *diplomats.DiplomatsTable) error {
 	for _, army := range self {
-		supplied := army.EvalSupplyRoute(r)
+		supplied := army.EvalSupplyRoute(r, friendlyFilter(army, d))
 		if !supplied {
 			army.SupplyState = CUTOFF_STARVING
+		} else {
+			army.SupplyState = ""
 		}
 	}
 	return nil
 }
 
-func (self *Army) EvalSupplyRoute(r regions.Regions) bool {
+// supply can only be drawn from home through regions held by the army's house,
+// its allies, or by no one at all. Castles besieged by an enemy cut the route.
+func (self *Army) EvalSupplyRoute(r regions.Regions, friendly regions.PathFilter) bool {
 	if self.Region == self.Home {
 		return true
 	}
-	// shortPath := r.Path(self.Region.Id, self.Home.Id, nil, nil)
-	// longPath := r.Path(self.Region.Id, self.Home.Id, hostileFilter, self)
-	// if len(longPath) != len(shortPath) {
-	// 	return false
-	// }
-	return true
+	return r.Path(self.Region.Id, self.Home.Id, friendly) != nil
+}
+
+// regions the army may pass freely, the region the army stands in always counts.
+func friendlyFilter(army *Army, d *diplomats.DiplomatsTable) regions.PathFilter {
+	return func(region *regions.Region) bool {
+		if region.Castle.Besieged() && !d.IsAlly(army.House, region.Castle.Besieger) {
+			return false
+		}
+		if region == army.Region || region.Controller == "" {
+			return true
+		}
+		return d.IsAlly(army.House, region.Controller)
+	}
 }
 
 func (self *Army) March(to *regions.Edge) error {
@@ -106,6 +145,7 @@ func (self *Army) ValidateMarch(edge *regions.Edge) (bool, error) {
 
 func newArmy(a *Army) *Army {
 	b := *a
+	b.Composition = a.Composition.copy()
 	return &b
 }
 
@@ -114,9 +154,13 @@ var SampleArmies = `
 startingRegion="region3cost"
 homeRegion="region1"
 house="house1"
+commander="eddard"
 morale = 3
 size = 30
 quality = 3
+[army1.composition]
+INFANTRY = 20
+CAVALRY = 10
 
 [army2]
 startingRegion="region1"
@@ -125,4 +169,7 @@ house="house2"
 morale = 4
 size = 30
 quality = 3
+[army2.composition]
+INFANTRY = 15
+ARCHERS = 15
 `
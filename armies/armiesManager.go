@@ -1,12 +1,14 @@
 package armies
 
 import (
-	"errors"
-	"fmt"
+	"container/heap"
 
 	"github.com/pgruenbacher/got/actions"
+	"github.com/pgruenbacher/got/characters"
 	"github.com/pgruenbacher/got/diplomats"
+	"github.com/pgruenbacher/got/economy"
 	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
 	"github.com/pgruenbacher/got/regions"
 )
 
@@ -35,6 +37,16 @@ const (
 	REDIRECT_ATTACK        Context = "REDIRECT_ATTACK"
 	MARCH                  Context = "MARCH"
 	CANCEL_NEUTRAL_PRESENT Context = "CANCEL_NEUTRAL_PRESENT"
+	// region is held by a neutral house that hasn't granted passage
+	CANCEL_NEUTRAL_TERRITORY Context = "CANCEL_NEUTRAL_TERRITORY"
+	// assault on a besieged castle
+	ASSAULT Context = "ASSAULT"
+	// march into a region where an enemy lies in wait
+	AMBUSH Context = "AMBUSH"
+	// attack on an enemy entering the region watched by the army
+	INTERCEPT Context = "INTERCEPT"
+	// march made only once its condition is met
+	CONDITIONAL Context = "CONDITIONAL"
 	/*
 	 *  Event Contexts
 	 */
@@ -48,6 +60,9 @@ const (
 	SURPRISE_ATTACK Context = "SURPRISE_ATTACK"
 	// Surprise retreat into a region with an unexpected enemy
 	SURPRISE_RETREAT Context = "SURPRISE_RETREAT"
+	// the condition of a conditional order was met, or it was not and the army held
+	TRIGGERED Context = "TRIGGERED"
+	HELD      Context = "HELD"
 )
 
 // Events
@@ -63,20 +78,69 @@ type MarchEvent struct {
 	Src regions.RegionId
 	Dst regions.RegionId
 	Ctx Context
+	// condition of a conditional order, nil for plain marches
+	Condition *Condition
+}
+
+// an army passed to another house, To is empty if the army became neutral
+type TransferEvent struct {
+	ArmyEvent
+	From families.HouseId
+	To   families.HouseId
+}
+
+// an army wiped out in battle or in an assault, taken off the map
+type DestructionEvent struct {
+	ArmyEvent
+	House  families.HouseId
+	Region regions.RegionId
 }
 
 // manager structeure
 type ArmiesManager struct {
-	Armies    Armies
-	regions   regions.Regions
-	diplomacy diplomats.DiplomatsTable
-	Config    Config
+	Armies     Armies
+	regions    regions.Regions
+	diplomacy  *diplomats.DiplomatsTable
+	characters characters.Characters
+	treasuries economy.Treasuries
+	// armies being raised, not yet in the field
+	musters []*muster
+	// supports standing until the marches of the turn are resolved
+	supports []SupportOrder
+	// hidden orders, likewise standing for the turn
+	ambushes     []AmbushOrder
+	intercepts   []InterceptOrder
+	conditionals []ConditionalOrder
+	// decides the outcome of each round of battle
+	resolver CombatResolver
+	Config   Config
 }
 
 type Config struct {
 	TerrainPenalties  TerrainPenalties
 	DefenseBonuses    map[regions.Terrain]CombatModifier `toml:"Defense_Bonuses",validate:"max=1,min=-1"`
 	ConstantModifiers map[Context]CombatModifier         `toml:"Context_Modifiers",validate:"max=1,min=-1"`
+	// bonus per point of commander skill above the lowest
+	CommanderModifier CombatModifier `toml:"Commander_Modifier"`
+	// turns an enemy must hold a region unopposed before it changes owner
+	OccupationTurns int `toml:"Occupation_Turns"`
+	// cost of the armies every turn
+	Upkeep      UpkeepRates      `toml:"Upkeep"`
+	Recruitment RecruitmentRates `toml:"Recruitment"`
+	// rest between battles
+	Recovery RecoveryRates `toml:"Recovery"`
+	// strengths and weaknesses of each unit type
+	Units map[UnitType]UnitProfile `toml:"Units"`
+	// experience gained in battle
+	Veterancy VeterancyRates `toml:"Veterancy"`
+	// model used to resolve battles
+	Resolver ResolverConfig `toml:"Resolver"`
+	Support  SupportRates   `toml:"Support"`
+	Ambush   AmbushRates    `toml:"Ambush"`
+	// how armies of each stance fight
+	Stances map[Stance]StanceProfile `toml:"Stances"`
+	// when orders are warned of
+	Validation ValidationRates `toml:"Validation"`
 }
 
 type TerrainPenalties map[regions.Terrain]TerrainPenalty
@@ -93,28 +157,49 @@ type Penalty struct {
  */
 
 // Armies Manager methods
-func (self *ArmiesManager) Init(a Armies, r regions.Regions, d diplomats.DiplomatsTable) error {
+func (self *ArmiesManager) Init(a Armies, r regions.Regions, d *diplomats.DiplomatsTable) error {
 	self.regions = r
 	self.diplomacy = d
 	self.Armies = a
-	self.Armies.Init(r)
+	if err := self.Armies.Init(r); err != nil {
+		return err
+	}
+	return self.validateCompositions()
+}
+
+func (self ArmiesManager) validateCompositions() error {
+	if len(self.Config.Units) == 0 {
+		return nil
+	}
+	for _, army := range self.Armies {
+		if err := army.validateComposition(self.Config.Units); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (self ArmiesManager) marchPrioritize(order MarchOrder) int {
 	var terrainPenalty, boundaryPenalty int
-	edge := self.regions[order.Src].Edges[order.Dst]
+	army, ok := self.Armies[order.ArmyId]
+	if !ok || army.Region.Id != order.Src {
+		return 0
+	}
+	edge, ok := army.Region.Edges[order.Dst]
+	if !ok {
+		return 0
+	}
 	if f, ok := self.Config.TerrainPenalties[edge.Src.Terrain]; ok {
 		if p, ok := f[edge.Dst.Terrain]; ok {
 			terrainPenalty = p.MovementPenalty
 		}
 	}
 	boundaryPenalty = edge.Boundary.MovePenalty()
-	return terrainPenalty + boundaryPenalty + self.Armies[order.ArmyId].Size
+	return terrainPenalty + boundaryPenalty + self.compositionPenalty(army) + army.Size
 }
 
 func (self *ArmiesManager) EvaluateArmies() error {
-	if err := self.Armies.EvalSupplies(self.regions); err != nil {
+	if err := self.Armies.EvalSupplies(self.regions, self.diplomacy); err != nil {
 		return err
 	}
 	return nil
@@ -129,6 +214,24 @@ func (self *ArmiesManager) ReadOrders(orders interface{}) (events events.EventsI
 		// do nothing
 	case []MarchOrder:
 		events, err = self.marchOrders(t)
+	case []AssaultOrder:
+		events, err = self.assaultOrders(t)
+	case []RecruitOrder:
+		events, err = self.recruitOrders(t)
+	case []MergeOrder:
+		events, err = self.mergeOrders(t)
+	case []SplitOrder:
+		events, err = self.splitOrders(t)
+	case []SupportOrder:
+		events, err = self.supportOrders(t)
+	case []AmbushOrder:
+		events, err = self.ambushOrders(t)
+	case []InterceptOrder:
+		events, err = self.interceptOrders(t)
+	case []ConditionalOrder:
+		events, err = self.conditionalOrders(t)
+	case []StanceOrder:
+		events, err = self.stanceOrders(t)
 	}
 	return events, err
 }
@@ -156,28 +259,155 @@ outerloop:
 	return orders
 }
 
+// TransferArmies hands the armies of one house to another. Their commanders
+// stay with their own house, and any levy service ends.
+func (self *ArmiesManager) TransferArmies(from, to families.HouseId) (events []TransferEvent) {
+	var transferred []armyId
+	for _, army := range self.Armies.OfHouse(from) {
+		transferred = append(transferred, army.Id)
+		army.House = to
+		army.LeviedBy = ""
+		army.Commander = ""
+		army.commander = nil
+		events = append(events, TransferEvent{
+			ArmyEvent: newArmyEvent(army.Id),
+			From:      from,
+			To:        to,
+		})
+	}
+	for _, army := range self.Armies {
+		if army.LeviedBy == from {
+			army.LeviedBy = ""
+		}
+	}
+	// the new house gave none of the orders its armies were standing by
+	self.dropOrders(transferred)
+	return events
+}
+
+// destroy removes an army with no men left, along with its standing orders
+func (self *ArmiesManager) destroy(id armyId) DestructionEvent {
+	army := self.Armies[id]
+	e := DestructionEvent{
+		ArmyEvent: newArmyEvent(id),
+		House:     army.House,
+		Region:    army.Region.Id,
+	}
+	delete(self.Armies, id)
+	self.dropOrders([]armyId{id})
+	return e
+}
+
+// dropOrders withdraws the standing orders of armies that were merged into
+// another, destroyed or handed to another house, and the supports given to them
+func (self *ArmiesManager) dropOrders(ids []armyId) {
+	supports := self.supports[:0]
+	for _, order := range self.supports {
+		if !listed(ids, order.ArmyId) && !listed(ids, order.Supported) {
+			supports = append(supports, order)
+		}
+	}
+	self.supports = supports
+	ambushes := self.ambushes[:0]
+	for _, order := range self.ambushes {
+		if !listed(ids, order.ArmyId) {
+			ambushes = append(ambushes, order)
+		}
+	}
+	self.ambushes = ambushes
+	intercepts := self.intercepts[:0]
+	for _, order := range self.intercepts {
+		if !listed(ids, order.ArmyId) {
+			intercepts = append(intercepts, order)
+		}
+	}
+	self.intercepts = intercepts
+	conditionals := self.conditionals[:0]
+	for _, order := range self.conditionals {
+		if !listed(ids, order.ArmyId) {
+			conditionals = append(conditionals, order)
+		}
+	}
+	self.conditionals = conditionals
+}
+
+func listed(ids []armyId, id armyId) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
 /*
  * individual order handling
  *
  */
 
 func (self *ArmiesManager) marchOrders(orders []MarchOrder) (e []MarchEvent, err error) {
+	e, _, _, err = self.resolveMarches(orders)
+	return e, err
+}
+
+// resolveMarches makes the marches of the turn and fights the battles they lead to
+func (self *ArmiesManager) resolveMarches(orders []MarchOrder) (e []MarchEvent, combats []CombatEvent, support []SupportEvent, err error) {
 	if err = self.validateMarchOrders(orders); err != nil {
-		return e, err
+		return e, combats, support, err
 	}
+	orders = firstMarches(orders)
 
+	self.withdrawSupports(orders)
+	self.withdrawStratagems(orders)
+	self.withdrawConditionals(orders)
 	tmpArmies := make(Armies, len(self.Armies))
 	copyArmies(tmpArmies, self.Armies)
 
-	events, battles, err := self.checkDestinations(tmpArmies, orders)
+	events, battles, err := self.checkDestinations(tmpArmies, orders, nil)
 	if err != nil {
-		return e, err
+		return e, combats, support, err
 	}
 	e = append(e, events...)
-	fmt.Println(tmpArmies["army1"].Size, tmpArmies["army1"].Morale, "amrmy2:", tmpArmies["army2"].Size, tmpArmies["army2"].Morale)
-	_, err = self.resolveBattles(battles)
-	fmt.Println(tmpArmies["army1"].Size, tmpArmies["army1"].Morale, "amrmy2:", tmpArmies["army2"].Size, tmpArmies["army2"].Morale)
-	return e, nil
+	// conditional orders are evaluated against the marches already made
+	r := resolution{tmpArmies, events, battles}
+	triggered, battles, conditionEvents := self.triggered(r)
+	e = append(e, conditionEvents...)
+	if len(triggered) > 0 {
+		events, more, err := self.checkDestinations(tmpArmies, triggered, r.marches())
+		if err != nil {
+			return e, combats, support, err
+		}
+		e = append(e, events...)
+		battles = append(battles, more...)
+	}
+	combats, support, err = self.resolveBattles(battles)
+	if err == nil {
+		self.commit(tmpArmies)
+		self.settleBattles(combats)
+	}
+	// supports, stratagems and conditions only last the turn
+	self.supports = nil
+	self.ambushes = nil
+	self.intercepts = nil
+	self.conditionals = nil
+	return e, combats, support, err
+}
+
+// only the first march order given to an army is carried out
+func firstMarches(orders []MarchOrder) (first []MarchOrder) {
+	for _, order := range orders {
+		if !marching(first, order.ArmyId) {
+			first = append(first, order)
+		}
+	}
+	return first
+}
+
+// commit carries the marches and battles fought on the copies over to the armies
+func (self *ArmiesManager) commit(tmpArmies Armies) {
+	for id, army := range tmpArmies {
+		*self.Armies[id] = *army
+	}
 }
 
 /*
@@ -193,12 +423,61 @@ func armiesWithin(a Armies, region *regions.Region) (armies []*Army) {
 	return armies
 }
 
-func (self *ArmiesManager) checkDestinations(tmpArmies Armies, orders []MarchOrder) (events []MarchEvent, combats battles, err error) {
+func (self *ArmiesManager) enemyWithin(army *Army, present []*Army) *Army {
+	for _, army2 := range present {
+		if self.diplomacy.IsEnemy(army.House, army2.House) {
+			return army2
+		}
+	}
+	return nil
+}
+
+// armies of the same house or coalition may stack within one region
+func (self *ArmiesManager) alliedWithin(army *Army, present []*Army) bool {
+	for _, army2 := range present {
+		if !self.diplomacy.IsAlly(army.House, army2.House) {
+			return false
+		}
+	}
+	return true
+}
+
+// only allies grant passage, and enemy territory may be invaded
+func (self *ArmiesManager) neutralTerritory(army *Army, region *regions.Region) bool {
+	if region.Controller == "" {
+		return false
+	}
+	return !self.diplomacy.IsAlly(army.House, region.Controller) && !self.diplomacy.IsEnemy(army.House, region.Controller)
+}
+
+func marchedThisTurn(marched []MarchOrder, id armyId) (MarchOrder, bool) {
+	for _, order := range marched {
+		if order.ArmyId == id {
+			return order, true
+		}
+	}
+	return MarchOrder{}, false
+}
+
+// an enemy that marched out of the region earlier in the turn
+func (self *ArmiesManager) departedEnemy(army *Army, region regions.RegionId, marched []MarchOrder, tmpArmies Armies) (*Army, MarchOrder, bool) {
+	for _, order := range marched {
+		enemy := tmpArmies[order.ArmyId]
+		if order.Src == region && self.diplomacy.IsEnemy(army.House, enemy.House) {
+			return enemy, order, true
+		}
+	}
+	return nil, MarchOrder{}, false
+}
+
+// marched are the marches already made this turn, in the order they were made
+func (self *ArmiesManager) checkDestinations(tmpArmies Armies, orders []MarchOrder, marched []MarchOrder) (events []MarchEvent, combats battles, err error) {
 	// create a temporary copy of the armies and their future destinations.
 	// perform movement penalties and army prioritizations for moves, then return queue of armies
 	pq := new(PriorityQueue)
 	for idx, order := range orders {
-		pq.Push(&Item{
+		// quicker armies reach their destinations first
+		heap.Push(pq, &Item{
 			value:    idx,
 			priority: 100 - self.marchPrioritize(order),
 		})
@@ -207,65 +486,112 @@ func (self *ArmiesManager) checkDestinations(tmpArmies Armies, orders []MarchOrd
 	// dstMap := make(map[regions.RegionId]armyId)
 outerLoop:
 	for pq.Len() > 0 {
-		fmt.Println("as")
-		order := orders[pq.Pop().(*Item).value]
-		army := tmpArmies[order.ArmyId]
-		// innerLoop:
-		for _, army2 := range armiesWithin(tmpArmies, self.regions[order.Dst]) {
-			// army may already be in combat, but continue other possible attack directions if it is returning attack or attacking different army
-			if self.diplomacy.IsEnemy(army.House, army2.House) {
-				// initiate attack on army within region
-				// set status of both armies as in combat
-				army.setInCombat()
-				army2.setInCombat()
-				switch order.Ctx {
-				// army attempted to retreat from battle, but is now caught in another battle by an enemy that had moved into that region quicker
-				case RETREAT:
-					events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, ATTACK))
-					fmt.Println("caught retreat!")
-					combats = append(combats, newBattle(army, army2, ATTACK))
-
-				case ATTACK:
-					// march event with attack refers to a successful intentional attack event
-					events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, ATTACK))
-					fmt.Println("attack!")
-					combats = append(combats, newBattle(army, army2, ATTACK))
-				case MARCH:
-					// march event with suprise attack refers to an unintentional attack of enemy in region
-					events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, SURPRISE_ATTACK))
-					fmt.Println("surprise attack!")
-					combats = append(combats, newBattle(army, army2, SURPRISE_ATTACK))
+		order := orders[heap.Pop(pq).(*Item).value]
+		army, ok := tmpArmies[order.ArmyId]
+		if !ok || army.Region.Id != order.Src {
+			// the army no longer stands where the order sets out from
+			continue outerLoop
+		}
+		if _, ok := army.Region.Edges[order.Dst]; !ok {
+			continue outerLoop
+		}
+		present := armiesWithin(tmpArmies, self.regions[order.Dst])
+		// army may already be in combat, but continue other possible attack directions if it is returning attack or attacking different army.
+		// enemies are looked for first, so an enemy sharing the region with its allies is still engaged
+		if ambusher := self.springAmbush(army, present); ambusher != nil {
+			// the army is surprised whatever its intent, and the ambush is revealed
+			army.setInCombat()
+			ambusher.setInCombat()
+			events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, AMBUSH))
+			combats = append(combats, newBattle(army, ambusher, SURPRISE_ATTACK))
+			continue outerLoop
+		}
+		if army2 := self.enemyWithin(army, present); army2 != nil {
+			// initiate attack on army within region
+			// set status of both armies as in combat
+			army.setInCombat()
+			army2.setInCombat()
+			switch order.Ctx {
+			// army attempted to retreat from battle, but is now caught in another battle by an enemy that had moved into that region quicker
+			case RETREAT:
+				events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, ATTACK))
+				combats = append(combats, newBattle(army, army2, ATTACK))
+
+			case ATTACK:
+				// march event with attack refers to a successful intentional attack event
+				events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, ATTACK))
+				if march, ok := marchedThisTurn(marched, army2.Id); ok {
+					// the enemy had only just arrived, and is caught before it can form up
+					events = append(events, newMarchEvent(army2.Id, march.Src, march.Dst, CAUGHT_ATTACK))
+					combats = append(combats, newBattle(army, army2, CAUGHT_ATTACK))
+					continue outerLoop
 				}
-				continue outerLoop
-
+				combats = append(combats, newBattle(army, army2, ATTACK))
+			case MARCH:
+				// march event with suprise attack refers to an unintentional attack of enemy in region
+				events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, SURPRISE_ATTACK))
+				combats = append(combats, newBattle(army, army2, SURPRISE_ATTACK))
 			}
-			// if army is being attacked, and not marching against an enemy then disregard other march orders
-			if army.inCombat() {
-				// cancel order
-				continue outerLoop
+			continue outerLoop
+		}
+		// if army is being attacked, and not marching against an enemy then disregard other march orders
+		if army.inCombat() {
+			// cancel order
+			continue outerLoop
+		}
+		if order.Ctx == ATTACK && self.pursues(army) {
+			if enemy, march, ok := self.departedEnemy(army, order.Dst, marched, tmpArmies); ok {
+				if _, ok := army.Region.Edges[enemy.Region.Id]; ok {
+					// the enemy moved next to the attacker, which turns to strike it there
+					army.setInCombat()
+					enemy.setInCombat()
+					events = append(events, newMarchEvent(order.ArmyId, order.Src, enemy.Region.Id, REDIRECT_ATTACK))
+					combats = append(combats, newBattle(army, enemy, REDIRECT_ATTACK))
+					continue outerLoop
+				}
+				if len(present) == 0 && !self.neutralTerritory(army, self.regions[order.Dst]) {
+					// the attacker advances into the abandoned region and falls upon the enemy on the march
+					army.setInCombat()
+					enemy.setInCombat()
+					army.March(army.Region.Edges[order.Dst])
+					marched = append(marched, order)
+					events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, ATTACK_PURSUIT))
+					events = append(events, newMarchEvent(enemy.Id, march.Src, march.Dst, CAUGHT_ATTACK))
+					combats = append(combats, newBattle(army, enemy, ATTACK_PURSUIT))
+					continue outerLoop
+				}
 			}
+		}
+		if len(present) > 0 {
 			// army is neither attacking nor being attacked
-			if self.diplomacy.IsAlly(army.House, army2.House) {
-				// army may move into region if there is an ally and there is no military
-				fmt.Println("allies! move in!")
+			if self.alliedWithin(army, present) {
+				// army may move into region if there are only allies (or coalition members) and there is no military
 				army.March(army.Region.Edges[order.Dst])
+				marched = append(marched, order)
 				events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, MARCH))
+				intercepts, e := self.intercepted(army, tmpArmies)
+				combats, events = append(combats, intercepts...), append(events, e...)
 				continue outerLoop
 			}
 			// ELSE there is a neutral army present, cannot enter the region, should not have been a legal move in the first place.
 			// since there is no idea if the neutral army will be staying or leaving to the player if it had been there in the first place.
 			// if quicker netural army moved there first...then tough luck.
 			// cancel army order
-			fmt.Println("cancel order")
 			events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, CANCEL_NEUTRAL_PRESENT))
 			continue outerLoop
 		}
 		// if army entering foreign region but not at war and does not have permission...
+		if self.neutralTerritory(army, self.regions[order.Dst]) {
+			events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, CANCEL_NEUTRAL_TERRITORY))
+			continue outerLoop
+		}
 
 		// if no armies present, then move on in!
 		army.March(army.Region.Edges[order.Dst])
+		marched = append(marched, order)
 		events = append(events, newMarchEvent(order.ArmyId, order.Src, order.Dst, MARCH))
-		fmt.Println("move army!")
+		intercepts, e := self.intercepted(army, tmpArmies)
+		combats, events = append(combats, intercepts...), append(events, e...)
 	}
 	return events, combats, nil
 }
@@ -276,32 +602,10 @@ outerLoop:
  */
 
 func (self *ArmiesManager) validateMarchOrders(orders []MarchOrder) error {
-outerloop:
-	for _, order := range orders {
-		// validate the army id
-		if _, ok := self.Armies[order.ArmyId]; !ok {
-			return errors.New(fmt.Sprintf("order %v had invalid armyId %v", order.Id, order.ArmyId))
-		}
-		army := self.Armies[order.ArmyId]
-		// validate src region
-		if _, ok := self.regions[order.Src]; !ok {
-			return errors.New(fmt.Sprintf("invalid src id %v", order.Src))
+	for _, v := range self.ValidateMarchOrders(orders) {
+		if err := v.Err(); err != nil {
+			return err
 		}
-		if army.Region.Id != order.Src {
-			return errors.New(fmt.Sprintf("army region %v doesn't match src %v", army.Region.Id, order.Src))
-		}
-		// validate destination region
-		_, ok := self.regions[order.Dst]
-		if !ok {
-			return errors.New(fmt.Sprintf("invalid destination id %v", order.Dst))
-		}
-		for _, edge := range army.Region.Edges {
-			if edge.Dst.Id == order.Dst {
-				continue outerloop
-			}
-		}
-		return errors.New(fmt.Sprintf("none of the army region  %v edges match army destination %v", army.Region.Id, order.Dst))
-
 	}
 	return nil
 }
@@ -381,10 +685,80 @@ var ExampleTerrainPenalty string = `
     `
 
 var ExampleModifiers string = `
+	Commander_Modifier = 0.05
+	Occupation_Turns = 2
 	[Defense_Bonuses]
 	PLAIN = 0.0
 	HILL = 0.1
 	MOUNTAIN = 0.3
 	[Context_Modifiers]
 	SURPRISE_ATTACK=-0.3
+	CAUGHT_ATTACK=-0.2
+	ATTACK_PURSUIT=0.1
+	REDIRECT_ATTACK=-0.1
+	INTERCEPT=0.1
+	[Upkeep]
+	Gold = 0.02
+	Food = 0.05
+	[Recruitment]
+	Gold = 0.1
+	SizePerManpower = 4
+	MaxQuality = 3
+	Morale = 3
+	MusterTurns = 2
+	[Recovery]
+	Morale = 1
+	HomeMorale = 1
+	MaxMorale = 5
+	Reinforcements = 2
+	HomeReinforcements = 5
+	[Support]
+	Share = 0.5
+	[Ambush]
+	Terrains = ["MOUNTAIN", "HILL"]
+	[Stances.AGGRESSIVE]
+	RetreatMorale = 0
+	RetreatSize = 0.0
+	Dealt = 1.2
+	Taken = 1.2
+	Pursues = true
+	[Stances.BALANCED]
+	RetreatMorale = 1
+	RetreatSize = 0.5
+	Pursues = true
+	[Stances.CAUTIOUS]
+	RetreatMorale = 2
+	RetreatSize = 0.75
+	Dealt = 0.9
+	Taken = 0.8
+	Pursues = false
+	[Resolver]
+	Name = "STANDARD"
+	Attrition = 0.25
+	DicePer = 5
+	HitOn = 6
+	HitDamage = 2
+	[Validation]
+	RiverPenalty = 10
+	[Veterancy]
+	Battle = 1
+	Victory = 2
+	PerQuality = 6
+	RecruitQuality = 2
+	[Units.INFANTRY]
+	MovementPenalty = 5
+	[Units.CAVALRY]
+	Strength = 0.2
+	[Units.CAVALRY.Terrain]
+	MOUNTAIN = -0.4
+	[Units.ARCHERS]
+	Strength = -0.1
+	MovementPenalty = 5
+	[Units.ARCHERS.Boundary]
+	RIVER = 0.3
+	WALL = 0.2
+	[Units.SIEGE]
+	Strength = -0.3
+	MovementPenalty = 20
+	Assault = 1.0
 	`
@@ -1,10 +1,14 @@
 package armies
 
 import (
+	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/BurntSushi/toml"
+	"github.com/pgruenbacher/got/characters"
 	"github.com/pgruenbacher/got/diplomats"
+	"github.com/pgruenbacher/got/events"
 	"github.com/pgruenbacher/got/families"
 	"github.com/pgruenbacher/got/regions"
 )
@@ -54,9 +58,24 @@ func TestArmies(t *testing.T) {
 		return
 	}
 	t.Log(armyManager.Config)
-	if err := armyManager.Init(armies, rs, table); err != nil {
+	if err := armyManager.Init(armies, rs, &table); err != nil {
 		t.Error(err)
 	}
+	var cs characters.Characters
+	if _, err := toml.Decode(characters.ExampleCharacters, &cs); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := cs.InitializeAll(h); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := armyManager.InitCharacters(cs); err != nil {
+		t.Error(err)
+	}
+	if err := armyManager.AssignCommander("army2", "eddard"); err == nil {
+		t.Error("commander of another house should be refused")
+	}
 
 	// orders := armyManager.GivePossibleOrders("army1")
 	// t.Log(orders)
@@ -70,3 +89,780 @@ func TestArmies(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestLevies(t *testing.T) {
+	a := duel("d")
+	a["vanguard"] = &Army{Size: 20, Quality: 3, Morale: 3, House: "house3", StartingRegion: "c", HomeRegion: "c"}
+	a["rearguard"] = &Army{Size: 10, Quality: 3, Morale: 3, House: "house3", StartingRegion: "c", HomeRegion: "c"}
+	armyManager := pursuitManager(t, a)
+	if _, err := armyManager.CallLevies("house9"); err == nil {
+		t.Error("only existing houses may call levies")
+	}
+	// mormont owes half its men, the vanguard alone covers it
+	e, err := armyManager.CallLevies("house1")
+	if err != nil || len(e) != 1 || e[0].ArmyId != "vanguard" {
+		t.Fatal("the vanguard should be levied", e, err)
+	}
+	if !armyManager.CanCommand("house1", "vanguard") || armyManager.CanCommand("house1", "rearguard") || !armyManager.CanCommand("house3", "vanguard") {
+		t.Error("house1 should command the levied vanguard only")
+	}
+	orders := Orders{March: []MarchOrder{
+		newMarchOrder("vanguard", "c", "e", MARCH),
+		newMarchOrder("rearguard", "c", "e", MARCH),
+	}}
+	p := armyManager.Command("house1", orders)
+	if len(p.Warnings) != 1 || armyManager.Armies["vanguard"].Region.Id != "e" || armyManager.Armies["rearguard"].Region.Id != "c" {
+		t.Error("only the march of the vanguard should be carried out", p.Warnings)
+	}
+	if e := armyManager.ReleaseLevies("house3"); len(e) != 1 || !e[0].Released || armyManager.CanCommand("house1", "vanguard") {
+		t.Error("the vanguard should be released", e)
+	}
+	if _, err := armyManager.CallLevies("house1"); err != nil {
+		t.Fatal(err)
+	}
+	rebellion, released, err := armyManager.Rebel("house3")
+	if err != nil || len(released) != 1 || armyManager.Armies["vanguard"].LeviedBy != "" {
+		t.Error("the levies of the rebels should return home", rebellion, released, err)
+	}
+	if e, _ := armyManager.CallLevies("house1"); len(e) != 0 {
+		t.Error("house1 has no vassals left to call", e)
+	}
+}
+
+func TestCommanderFate(t *testing.T) {
+	jaime := &characters.Character{Id: "jaime", House: "house2", Status: characters.ALIVE}
+	target := &Army{Id: "target", House: "house2", commander: jaime}
+	by := &Army{Id: "by", House: "house1"}
+	var e CombatEvent
+	for i := 0; i < 100 && e.fate == nil; i++ {
+		e = commanderFate(newCombatEvent(target.Id, by.Id, DESTROYED), target, by, nil)
+	}
+	if e.fate == nil || !jaime.Available() || e.Commander != nil {
+		t.Fatal("the fate of the commander should wait for the battle results to stand", e)
+	}
+	// the fate is drawn from the source of the resolver
+	var fates []string
+	for i := 0; i < 2; i++ {
+		r := rand.New(rand.NewSource(5))
+		for j := 0; j < 10; j++ {
+			fates = append(fates, fmt.Sprint(commanderFate(newCombatEvent(target.Id, by.Id, DESTROYED), target, by, r).fate))
+		}
+	}
+	if fmt.Sprint(fates[:10]) != fmt.Sprint(fates[10:]) {
+		t.Error("fates drawn from the same seed should come out alike", fates)
+	}
+	combats := []CombatEvent{e}
+	settleFates(combats)
+	if jaime.Available() || combats[0].Commander == nil || combats[0].Commander.Status != jaime.Status {
+		t.Error("the commander should have fallen or been captured", combats[0])
+	}
+}
+
+func TestBattlesSettled(t *testing.T) {
+	a := duel("b")
+	a["enemy"].Size, a["enemy"].DefenseState = 1, 1
+	armyManager := pursuitManager(t, a)
+	wall := &regions.Wall{Name: "wall", Borders: []regions.RegionId{"a", "b"}, Strength: 4}
+	if err := armyManager.regions.IncorporateBoundary(wall); err != nil {
+		t.Fatal(err)
+	}
+	tmpArmies := make(Armies)
+	copyArmies(tmpArmies, armyManager.Armies)
+	if _, _, err := armyManager.resolveBattles(battles{{tmpArmies["attacker"], tmpArmies["enemy"], ATTACK}}); err != nil || wall.Damaged != 0 {
+		t.Error("the wall should stand until the results of the battle do", wall, err)
+	}
+	_, combats, _, err := armyManager.resolveMarches([]MarchOrder{newMarchOrder("attacker", "a", "b", ATTACK)})
+	if err != nil || len(combats) != 1 {
+		t.Fatal("expected a battle in b", combats, err)
+	}
+	if wall.Damaged != 1 {
+		t.Error("the wall should be worn down by the attack", wall)
+	}
+	if _, ok := armyManager.Armies["enemy"]; ok || len(combats[0].Destroyed) != 1 || combats[0].Destroyed[0].ArmyId != "enemy" {
+		t.Error("the enemy should be wiped out and removed", combats[0])
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	a := duel("b")
+	a["attacker"].Morale = 1
+	a["enemy"].DefenseState = 1
+	armyManager := pursuitManager(t, a)
+	armyManager.regions["a"].Owner, armyManager.regions["a"].Controller = "house1", "house1"
+	if _, err := armyManager.ReadOrders([]MarchOrder{newMarchOrder("attacker", "a", "b", ATTACK)}); err != nil {
+		t.Fatal(err)
+	}
+	attacker := armyManager.Armies["attacker"]
+	morale := attacker.Morale
+	armyManager.EvalRecovery()
+	if attacker.Morale != morale {
+		t.Error("the attacker fought this turn and can't rest", attacker.Morale)
+	}
+	armyManager.EndBattles()
+	armyManager.EvalRecovery()
+	if attacker.Morale <= morale {
+		t.Error("the attacker should rest once the battle is over", attacker.Morale)
+	}
+}
+
+func TestComposition(t *testing.T) {
+	var armyManager ArmiesManager
+	if _, err := toml.Decode(ExampleModifiers, &armyManager.Config); err != nil {
+		t.Error(err)
+		return
+	}
+	cases := []struct {
+		composition Composition
+		valid       bool
+	}{
+		{Composition{INFANTRY: 20, CAVALRY: 10}, true},
+		{Composition{INFANTRY: 20}, false},
+		{Composition{INFANTRY: 40, CAVALRY: -10}, false},
+		{Composition{INFANTRY: 20, "DRAGONS": 10}, false},
+	}
+	for _, c := range cases {
+		army := Army{Id: "army", Size: 30, Composition: c.composition}
+		if err := army.validateComposition(armyManager.Config.Units); (err == nil) != c.valid {
+			t.Error("composition", c.composition, "should be valid", c.valid, err)
+		}
+	}
+}
+
+func TestVeterancy(t *testing.T) {
+	var armyManager ArmiesManager
+	if _, err := toml.Decode(ExampleModifiers, &armyManager.Config); err != nil {
+		t.Error(err)
+		return
+	}
+	winner := &Army{Id: "winner", Size: 20, Quality: 4}
+	loser := &Army{Id: "loser", Size: 10, Quality: 2}
+	e := armyManager.gainExperience(newCombatEvent(loser.Id, winner.Id, DEFEATED), loser, winner)
+	if len(e) != 2 || loser.Experience != 1 || winner.Experience != 3 {
+		t.Error("both survivors should gain experience, the victor most", e)
+	}
+	armyManager.gainExperience(newCombatEvent(loser.Id, winner.Id, DEFEATED), loser, winner)
+	if winner.Quality != 5 || winner.Experience != 0 {
+		t.Error("experience should raise quality", winner)
+	}
+	winner.Size = 40
+	armyManager.dilute(winner, 20)
+	if winner.Quality != 4 {
+		t.Error("fresh recruits should dilute quality", winner)
+	}
+}
+
+func TestBattleResults(t *testing.T) {
+	a := duel("b")
+	a["enemy"].DefenseState = 1
+	armyManager := pursuitManager(t, a)
+	e, err := armyManager.ReadOrders([]MarchOrder{newMarchOrder("attacker", "a", "b", ATTACK)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attacker, enemy := armyManager.Armies["attacker"], armyManager.Armies["enemy"]
+	if attacker.Size == 30 && enemy.Size == 10 {
+		t.Error("the losses of the battle should reach the armies", e)
+	}
+	if attacker.Experience == 0 && attacker.Quality == 3 {
+		t.Error("the experience of the battle should reach the armies", attacker)
+	}
+	if attacker.Region.Id != "a" || !attacker.inCombat() {
+		t.Error("the attacker should have fought from a", attacker.Region.Id)
+	}
+
+	armyManager = pursuitManager(t, duel("d"))
+	if _, err := armyManager.ReadOrders([]MarchOrder{newMarchOrder("attacker", "a", "c", MARCH)}); err != nil {
+		t.Fatal(err)
+	}
+	if armyManager.Armies["attacker"].Region.Id != "c" {
+		t.Error("the attacker should have marched into c")
+	}
+}
+
+func TestResolvers(t *testing.T) {
+	var armyManager ArmiesManager
+	if _, err := toml.Decode(ExampleModifiers, &armyManager.Config); err != nil {
+		t.Error(err)
+		return
+	}
+	armyManager.Config.Resolver.Name = LANCHESTER_RESOLVER
+	resolver, err := armyManager.combatResolver()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	large := &Army{Id: "large", Size: 40, Quality: 2, Morale: 3}
+	small := &Army{Id: "small", Size: 20, Quality: 2, Morale: 3}
+	e := resolver.Resolve(small, large, 0, 0)
+	// each side inflicts a quarter of its strength
+	if e.Ctx != DEFEATED || e.TargetArmy != "small" || small.Size != 10 || large.Size != 35 {
+		t.Error("lanchester model should favour the larger army", e, small, large)
+	}
+	armyManager.Config.Resolver.Name = "CHESS"
+	if _, err := armyManager.combatResolver(); err == nil {
+		t.Error("unknown resolvers should be rejected")
+	}
+}
+
+func TestModifiers(t *testing.T) {
+	var armyManager ArmiesManager
+	if _, err := toml.Decode(ExampleModifiers, &armyManager.Config); err != nil {
+		t.Error(err)
+		return
+	}
+	armyManager.Config.Resolver.Name = LANCHESTER_RESOLVER
+	hill := &regions.Region{Id: "hill", Terrain: "HILL", Edges: make(map[regions.RegionId]*regions.Edge)}
+	plain := &regions.Region{Id: "plain", Terrain: regions.Plain, Edges: make(map[regions.RegionId]*regions.Edge)}
+	river := &regions.River{AttackingPenalty: -0.2}
+	plain.Edges[hill.Id] = &regions.Edge{Src: plain, Dst: hill, Boundary: river}
+	hill.Edges[plain.Id] = &regions.Edge{Src: hill, Dst: plain, Boundary: river}
+
+	defender := &Army{Id: "defender", Size: 40, Quality: 2, Morale: 3, Region: hill, DefenseState: 1}
+	attacker := &Army{Id: "attacker", Size: 40, Quality: 2, Morale: 3, Region: plain}
+	other1 := &Army{Id: "other1", Size: 40, Quality: 2, Morale: 3, Region: plain}
+	other2 := &Army{Id: "other2", Size: 40, Quality: 2, Morale: 3, Region: plain}
+	events, _, err := armyManager.resolveBattles(battles{
+		newBattle(attacker, defender, ATTACK),
+		newBattle(other1, other2, ATTACK),
+		newBattle(other2, other1, ATTACK),
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(events) != 3 || events[0].TargetArmy != "attacker" {
+		t.Error("the defender on the hill should win", events)
+		return
+	}
+	if len(events[0].TargetModifiers) != 1 || events[0].TargetModifiers[0].Source != BOUNDARY_MODIFIER ||
+		len(events[0].ByModifiers) != 1 || events[0].ByModifiers[0].Source != TERRAIN_MODIFIER {
+		t.Error("the breakdown should explain the battle", events[0])
+	}
+	if events[1].Ctx != DRAW || len(events[1].TargetModifiers) != 0 || len(events[1].ByModifiers) != 0 {
+		t.Error("modifiers should not leak into the next battle", events[1])
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	var armyManager ArmiesManager
+	if _, err := toml.Decode(ExampleModifiers, &armyManager.Config); err != nil {
+		t.Error(err)
+		return
+	}
+	var s Scenario
+	if _, err := toml.Decode(ExampleScenario, &s); err != nil {
+		t.Error(err)
+		return
+	}
+	odds, err := armyManager.Simulate(s)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	again, _ := armyManager.Simulate(s)
+	if odds.AttackerLosses != again.AttackerLosses || odds.Defender[DEFEATED] != again.Defender[DEFEATED] {
+		t.Error("simulations with the same seed should agree", odds, again)
+	}
+	var total float32
+	for ctx, p := range odds.Attacker {
+		if ctx != DRAW {
+			total = total + p
+		}
+	}
+	for _, p := range odds.Defender {
+		total = total + p
+	}
+	if total < 0.99 || total > 1.01 || odds.AttackerLosses <= 0 || odds.DefenderLosses <= 0 {
+		t.Error("outcomes should cover every run", odds)
+	}
+	if s.Attacker.Size != 40 {
+		t.Error("the scenario armies should be left untouched")
+	}
+	t.Log(odds)
+}
+
+// a, b and c border each other, d lies beyond b and e beyond c
+var pursuitRegions = `
+	[a]
+	neighbors = ["b", "c"]
+	[b]
+	neighbors = ["a", "c", "d"]
+	[c]
+	neighbors = ["a", "b", "e"]
+	[d]
+	neighbors = ["b"]
+	[e]
+	neighbors = ["c"]
+`
+
+// the attacker of house1 in a, and a smaller enemy of house2. house3 is sworn to house1
+func duel(enemyAt regions.RegionId) Armies {
+	return Armies{
+		"attacker": &Army{Size: 30, Quality: 3, Morale: 3, House: "house1", StartingRegion: "a", HomeRegion: "a"},
+		"enemy":    &Army{Size: 10, Quality: 3, Morale: 3, House: "house2", StartingRegion: enemyAt, HomeRegion: enemyAt},
+	}
+}
+
+func pursuitManager(t *testing.T, a Armies) ArmiesManager {
+	var armyManager ArmiesManager
+	var rs regions.Regions
+	if _, err := toml.Decode(pursuitRegions, &rs); err != nil {
+		t.Fatal(err)
+	}
+	if err := rs.ConnectAll(); err != nil {
+		t.Fatal(err)
+	}
+	var h families.Houses
+	if _, err := toml.Decode(families.ExampleVassals, &h); err != nil {
+		t.Fatal(err)
+	}
+	h.InitializeAll()
+	var table diplomats.DiplomatsTable
+	if _, err := toml.Decode(diplomats.ExampleVassalTable, &table); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Init(h); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := toml.Decode(ExampleModifiers, &armyManager.Config); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Init(rs); err != nil {
+		t.Fatal(err)
+	}
+	if err := armyManager.Init(a, rs, &table); err != nil {
+		t.Fatal(err)
+	}
+	return armyManager
+}
+
+func TestPursuit(t *testing.T) {
+	cases := []struct {
+		from, to regions.RegionId
+		ctx      Context
+		field    regions.RegionId
+	}{
+		// the enemy marches into the region under attack
+		{"c", "b", CAUGHT_ATTACK, "a"},
+		// the enemy leaves the region under attack for one out of reach
+		{"b", "d", ATTACK_PURSUIT, "b"},
+		// the enemy leaves the region under attack for one next to the attacker
+		{"b", "c", REDIRECT_ATTACK, "a"},
+	}
+	for _, c := range cases {
+		armyManager := pursuitManager(t, duel(c.from))
+		orders := []MarchOrder{
+			newMarchOrder("enemy", c.from, c.to, MARCH),
+			newMarchOrder("attacker", "a", "b", ATTACK),
+		}
+		tmpArmies := make(Armies)
+		copyArmies(tmpArmies, armyManager.Armies)
+		events, combats, err := armyManager.checkDestinations(tmpArmies, orders, nil)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if len(combats) != 1 || combats[0].ctx != c.ctx || combats[0].army2.Id != "enemy" {
+			t.Error("expected battle", c.ctx, combats, events)
+			continue
+		}
+		if tmpArmies["attacker"].Region.Id != c.field {
+			t.Error(c.ctx, "attacker should stand in", c.field, tmpArmies["attacker"].Region.Id)
+		}
+		e, _ := engage(map[armyId]armyId{}, combats[0])
+		modifiers := armyManager.modifiers(e, combats[0].army1)
+		modifiers = append(modifiers, armyManager.modifiers(e, combats[0].army2)...)
+		found := false
+		for _, m := range modifiers {
+			if m.Source == CONTEXT_MODIFIER && m.Value == armyManager.Config.ConstantModifiers[c.ctx] {
+				found = true
+			}
+		}
+		if !found {
+			t.Error(c.ctx, "modifier should be applied", modifiers)
+		}
+	}
+}
+
+// the battle fought between the two armies, either way round
+func battleOf(combats []CombatEvent, army1, army2 armyId) *CombatEvent {
+	for _, c := range combats {
+		if (c.TargetArmy == army1 && c.ByArmy == army2) || (c.TargetArmy == army2 && c.ByArmy == army1) {
+			return &c
+		}
+	}
+	return nil
+}
+
+func marchOf(e []MarchEvent, id armyId, ctx Context) *MarchEvent {
+	for _, event := range e {
+		if event.ArmyId == id && event.Ctx == ctx {
+			return &event
+		}
+	}
+	return nil
+}
+
+// the attacker strikes the defended enemy in b with support from c, unless the raider from e cuts it
+func supportArmies() Armies {
+	a := duel("b")
+	a["enemy"].DefenseState = 1
+	a["supporter"] = &Army{Size: 20, Quality: 3, Morale: 3, House: "house1", StartingRegion: "c", HomeRegion: "c"}
+	a["raider"] = &Army{Size: 10, Quality: 3, Morale: 3, House: "house2", StartingRegion: "e", HomeRegion: "e"}
+	return a
+}
+
+func TestSupport(t *testing.T) {
+	cases := []struct {
+		raid      bool
+		ctx       SupportContext
+		supported bool
+	}{
+		{false, SUPPORT_GIVEN, true},
+		{true, SUPPORT_CUT, false},
+	}
+	for _, c := range cases {
+		armyManager := pursuitManager(t, supportArmies())
+		support := SupportOrder{ArmyOrder: newArmyOrder("supporter"), Supported: "attacker", Region: "b"}
+		if _, err := armyManager.ReadOrders([]SupportOrder{support}); err != nil {
+			t.Error(err)
+			return
+		}
+		orders := []MarchOrder{newMarchOrder("attacker", "a", "b", ATTACK)}
+		if c.raid {
+			orders = append(orders, newMarchOrder("raider", "e", "c", ATTACK))
+		}
+		_, combats, supportEvents, err := armyManager.resolveMarches(orders)
+		battle := battleOf(combats, "attacker", "enemy")
+		if err != nil || battle == nil || len(supportEvents) != 1 {
+			t.Error("expected a battle in b and a single support event", combats, supportEvents, err)
+			return
+		}
+		modifiers := battle.ByModifiers
+		if battle.TargetArmy == "attacker" {
+			modifiers = battle.TargetModifiers
+		}
+		supported := false
+		for _, m := range modifiers {
+			supported = supported || m.Source == SUPPORT_MODIFIER
+		}
+		if supported != c.supported || supportEvents[0].Ctx != c.ctx {
+			t.Error("support should be", c.ctx, modifiers, supportEvents)
+		}
+	}
+	armyManager := pursuitManager(t, supportArmies())
+	if _, err := armyManager.ReadOrders([]SupportOrder{{ArmyOrder: newArmyOrder("enemy"), Supported: "attacker", Region: "a"}}); err == nil {
+		t.Error("enemies should not support each other")
+	}
+}
+
+func TestAmbush(t *testing.T) {
+	cases := []struct {
+		name    string
+		enemyAt regions.RegionId
+		terrain regions.Terrain
+		orders  Orders
+		refused bool
+		// the march of the battle that springs the stratagem
+		army armyId
+		ctx  Context
+	}{
+		{"hidden in the open", "b", regions.Plain, Orders{Ambush: []AmbushOrder{{newArmyOrder("enemy")}}}, true, "", ""},
+		{"hidden in the mountains", "b", regions.Mountain, Orders{Ambush: []AmbushOrder{{newArmyOrder("enemy")}}}, false, "attacker", AMBUSH},
+		{"watching a region out of reach", "d", regions.Plain, Orders{Intercept: []InterceptOrder{{newArmyOrder("enemy"), "c"}}}, true, "", ""},
+		{"watching b from d", "d", regions.Plain, Orders{Intercept: []InterceptOrder{{newArmyOrder("enemy"), "b"}}}, false, "enemy", INTERCEPT},
+	}
+	for _, c := range cases {
+		armyManager := pursuitManager(t, duel(c.enemyAt))
+		armyManager.regions["b"].Terrain = c.terrain
+		p := armyManager.Command("house2", c.orders)
+		if c.refused {
+			if len(p.Warnings) == 0 {
+				t.Error(c.name, "should be refused")
+			}
+			continue
+		}
+		if len(p.Warnings) != 0 || len(p.Events) != 1 {
+			t.Error(c.name, "should be set", p)
+			continue
+		}
+		if len(events.Visible("house1", p.Events)) != 0 {
+			t.Error(c.name, "should be hidden from other houses", p.Events)
+		}
+		if ambushes, intercepts := armyManager.HiddenOrders("house1"); len(ambushes) != 0 || len(intercepts) != 0 {
+			t.Error(c.name, "should only be disclosed to its house")
+		}
+		marches, combats, _, err := armyManager.resolveMarches([]MarchOrder{newMarchOrder("attacker", "a", "b", MARCH)})
+		if err != nil || marchOf(marches, c.army, c.ctx) == nil || battleOf(combats, "attacker", "enemy") == nil {
+			t.Error(c.name, "should fall upon the marching army", marches, combats, err)
+		}
+	}
+}
+
+// the enemy and its reserve stand in d, watching b
+func reserveArmies() Armies {
+	a := duel("d")
+	a["reserve"] = &Army{Size: 10, Quality: 3, Morale: 3, House: "house2", StartingRegion: "d", HomeRegion: "d"}
+	return a
+}
+
+func TestStratagemsLapse(t *testing.T) {
+	merge := MergeOrder{ArmyOrder: newArmyOrder("reserve"), Others: []armyId{"enemy"}}
+	cases := []struct {
+		name  string
+		house families.HouseId
+		lapse func(*ArmiesManager) error
+	}{
+		{"merged away", "house2", func(self *ArmiesManager) error {
+			_, err := self.ReadOrders([]MergeOrder{merge})
+			return err
+		}},
+		{"changing hands", "house3", func(self *ArmiesManager) error {
+			self.TransferArmies("house2", "house3")
+			return nil
+		}},
+	}
+	for _, c := range cases {
+		armyManager := pursuitManager(t, reserveArmies())
+		if _, err := armyManager.ReadOrders([]InterceptOrder{{newArmyOrder("enemy"), "b"}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.lapse(&armyManager); err != nil {
+			t.Fatal(err)
+		}
+		if _, intercepts := armyManager.HiddenOrders(c.house); len(intercepts) != 0 || armyManager.Check() != nil {
+			t.Error("the intercept should lapse once its army is", c.name, intercepts)
+		}
+	}
+}
+
+func TestConditionalOrders(t *testing.T) {
+	cases := []struct {
+		name        string
+		enemyAt     regions.RegionId
+		conditional ConditionalOrder
+		march       MarchOrder
+		// the condition event, hidden from the house giving the march
+		army  armyId
+		ctx   Context
+		house families.HouseId
+		// the march that should follow, or not
+		follower  armyId
+		followCtx Context
+		follows   bool
+	}{
+		{
+			"the enemy retreats if attacked by a stronger army", "b",
+			ConditionalOrder{ArmyOrder: newArmyOrder("enemy"), If: Condition{Kind: ATTACKED_BY, Strength: 20}, Then: newMarchOrder("enemy", "b", "d", RETREAT)},
+			newMarchOrder("attacker", "a", "b", ATTACK),
+			"enemy", TRIGGERED, "house1",
+			"enemy", MARCH, true,
+		},
+		{
+			"the attacker strikes once the enemy enters b", "d",
+			ConditionalOrder{ArmyOrder: newArmyOrder("attacker"), If: Condition{Kind: ENEMY_ENTERS, Region: "b"}, Then: newMarchOrder("attacker", "a", "b", ATTACK)},
+			newMarchOrder("enemy", "d", "b", MARCH),
+			"attacker", TRIGGERED, "house2",
+			"enemy", CAUGHT_ATTACK, true,
+		},
+		{
+			"the attacker holds while the enemy leaves b", "b",
+			ConditionalOrder{ArmyOrder: newArmyOrder("attacker"), If: Condition{Kind: ENEMY_ENTERS, Region: "b"}, Then: newMarchOrder("attacker", "a", "b", ATTACK)},
+			newMarchOrder("enemy", "b", "d", MARCH),
+			"attacker", HELD, "house2",
+			"attacker", ATTACK, false,
+		},
+	}
+	for _, c := range cases {
+		armyManager := pursuitManager(t, duel(c.enemyAt))
+		if _, err := armyManager.ReadOrders([]ConditionalOrder{c.conditional}); err != nil {
+			t.Error(c.name, err)
+			continue
+		}
+		e, err := armyManager.ReadOrders([]MarchOrder{c.march})
+		if err != nil {
+			t.Error(c.name, err)
+			continue
+		}
+		marches := e.([]MarchEvent)
+		if marchOf(marches, c.army, c.ctx) == nil {
+			t.Error(c.name, "expected", c.ctx, marches)
+		}
+		for _, event := range events.Visible(string(c.house), appendEvents(nil, marches)) {
+			if event.(MarchEvent).Condition != nil {
+				t.Error(c.name, "the condition should only be disclosed to its house", event)
+			}
+		}
+		if (marchOf(marches, c.follower, c.followCtx) != nil) != c.follows {
+			t.Error(c.name, "expected", c.followCtx, c.follows, marches)
+		}
+		if c.conditional.Then.Ctx == RETREAT {
+			if army := armyManager.Armies[c.army]; army.Region.Id != c.conditional.Then.Dst || army.inCombat() {
+				t.Error(c.name, "the army should have slipped away to", c.conditional.Then.Dst, army.Region.Id)
+			}
+		}
+	}
+}
+
+func TestConditionsLapse(t *testing.T) {
+	// the enemy would strike whoever enters b, but is merged into the reserve first
+	armyManager := pursuitManager(t, reserveArmies())
+	strike := ConditionalOrder{
+		ArmyOrder: newArmyOrder("enemy"),
+		If:        Condition{Kind: ENEMY_ENTERS, Region: "b"},
+		Then:      newMarchOrder("enemy", "d", "b", ATTACK),
+	}
+	if _, err := armyManager.ReadOrders([]ConditionalOrder{strike}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := armyManager.ReadOrders([]MergeOrder{{ArmyOrder: newArmyOrder("reserve"), Others: []armyId{"enemy"}}}); err != nil {
+		t.Fatal(err)
+	}
+	marches, _, _, err := armyManager.resolveMarches([]MarchOrder{newMarchOrder("attacker", "a", "b", MARCH)})
+	if err != nil || marchOf(marches, "enemy", TRIGGERED) != nil || marchOf(marches, "enemy", HELD) != nil {
+		t.Error("the conditional order should lapse once its army is merged away", marches, err)
+	}
+}
+
+func TestStance(t *testing.T) {
+	var a Armies
+	stances := `
+	[bold]
+	stance = "AGGRESSIVE"
+	[wary]
+	stance = "CAUTIOUS"
+	[plain]
+	`
+	if _, err := toml.Decode(stances, &a); err != nil {
+		t.Error(err)
+		return
+	}
+	if a["bold"].stance() != AGGRESSIVE || a["wary"].stance() != CAUTIOUS || a["plain"].stance() != BALANCED {
+		t.Error("stance should be read from the armies", a)
+	}
+
+	// the enemy leaves b for d as the attacker strikes
+	armyManager := pursuitManager(t, duel("b"))
+	if _, err := armyManager.ReadOrders([]StanceOrder{{newArmyOrder("attacker"), "RECKLESS"}}); err == nil {
+		t.Error("unknown stances should be refused")
+	}
+	if _, err := armyManager.ReadOrders([]StanceOrder{{newArmyOrder("attacker"), CAUTIOUS}}); err != nil {
+		t.Error(err)
+		return
+	}
+	_, combats, _, err := armyManager.resolveMarches([]MarchOrder{
+		newMarchOrder("enemy", "b", "d", MARCH),
+		newMarchOrder("attacker", "a", "b", ATTACK),
+	})
+	if err != nil || len(combats) != 0 {
+		t.Error("a cautious army should not pursue", combats, err)
+	}
+
+	// the armies strike at each other, the cautious enemy gives up the field
+	armyManager = pursuitManager(t, duel("b"))
+	armyManager.Config.Resolver.Name = LANCHESTER_RESOLVER
+	if _, err := armyManager.ReadOrders([]StanceOrder{{newArmyOrder("attacker"), AGGRESSIVE}, {newArmyOrder("enemy"), CAUTIOUS}}); err != nil {
+		t.Error(err)
+		return
+	}
+	_, combats, _, err = armyManager.resolveMarches([]MarchOrder{
+		newMarchOrder("attacker", "a", "b", ATTACK),
+		newMarchOrder("enemy", "b", "a", ATTACK),
+	})
+	if err != nil || len(combats) == 0 {
+		t.Error(err)
+		return
+	}
+	if combats[0].Ctx != RETREATED || combats[0].TargetArmy != "enemy" {
+		t.Error("the cautious army should give up the field early", combats[0])
+	}
+}
+
+// the attacker in c is supplied from e through c, which the enemy holds, and the trident runs between a and c
+func validationManager(t *testing.T) ArmiesManager {
+	armyManager := pursuitManager(t, Armies{
+		"attacker": &Army{Size: 30, Quality: 3, Morale: 3, House: "house1", StartingRegion: "c", HomeRegion: "e"},
+		"enemy":    &Army{Size: 10, Quality: 3, Morale: 3, House: "house2", StartingRegion: "d", HomeRegion: "d"},
+		"neutral":  &Army{Size: 10, Quality: 1, Morale: 1, House: "house4", StartingRegion: "b", HomeRegion: "b"},
+	})
+	armyManager.regions["c"].Controller = "house2"
+	trident := &regions.River{Name: "trident", Borders: []regions.RegionId{"a", "c"}, MovementPenalty: 20}
+	if err := armyManager.regions.IncorporateBoundary(trident); err != nil {
+		t.Fatal(err)
+	}
+	return armyManager
+}
+
+func TestMarchValidation(t *testing.T) {
+	armyManager := validationManager(t)
+	orders := []MarchOrder{
+		newMarchOrder("attacker", "c", "a", MARCH),
+		newMarchOrder("attacker", "c", "b", MARCH),
+		newMarchOrder("enemy", "b", "d", MARCH),
+		newMarchOrder("ghost", "a", "b", MARCH),
+		newMarchOrder("enemy", "d", "e", MARCH),
+	}
+	expected := [][]ValidationCode{
+		{RIVER_CROSSING, LEAVES_SUPPLY},
+		{NEUTRAL_PRESENT, LEAVES_SUPPLY, DUPLICATE_ORDER},
+		{WRONG_SOURCE},
+		{UNKNOWN_ARMY},
+		{MISSING_EDGE, DUPLICATE_ORDER},
+	}
+	results := armyManager.ValidateMarchOrders(orders)
+	for i, v := range results {
+		var codes []ValidationCode
+		for _, f := range v.Findings {
+			codes = append(codes, f.Code)
+		}
+		if fmt.Sprint(codes) != fmt.Sprint(expected[i]) {
+			t.Error("unexpected findings for order", i, v.Findings)
+		}
+	}
+	if results[0].Err() != nil || len(results[1].Warnings()) != 3 || results[1].Err() != nil || results[2].Err() == nil {
+		t.Error("warnings should not be errors", results)
+	}
+	if err := armyManager.validateMarchOrders(orders); err == nil || err.Error() != results[2].Err().Error() {
+		t.Error("the first error should still refuse the orders", err)
+	}
+
+	// only the first of the two marches is carried out
+	armyManager = validationManager(t)
+	twice := []MarchOrder{
+		newMarchOrder("attacker", "c", "e", MARCH),
+		newMarchOrder("attacker", "c", "a", MARCH),
+	}
+	if _, err := armyManager.ReadOrders(twice); err != nil {
+		t.Error(err)
+	}
+	if armyManager.Armies["attacker"].Region.Id != "e" {
+		t.Error("the attacker should have marched into e", armyManager.Armies["attacker"].Region.Id)
+	}
+	// a march setting out from where the army no longer stands is passed over
+	tmpArmies := make(Armies)
+	copyArmies(tmpArmies, armyManager.Armies)
+	if e, combats, err := armyManager.checkDestinations(tmpArmies, twice[1:], nil); err != nil || len(e) != 0 || len(combats) != 0 {
+		t.Error("the stale march should be passed over", e, combats, err)
+	}
+}
+
+func TestCloneResolver(t *testing.T) {
+	armyManager := pursuitManager(t, supportArmies())
+	source := rand.New(rand.NewSource(7))
+	armyManager.SetCombatResolver(StandardResolver{Rand: source})
+	var outcomes []string
+	for i := 0; i < 2; i++ {
+		clone := armyManager.clone(3)
+		_, combats, _, err := clone.resolveMarches([]MarchOrder{newMarchOrder("attacker", "a", "b", ATTACK)})
+		if err != nil || len(combats) != 1 {
+			t.Fatal("expected a battle in b", combats, err)
+		}
+		outcomes = append(outcomes, fmt.Sprint(combats[0].Ctx, clone.Armies["attacker"].Size, clone.Armies["enemy"].Size))
+	}
+	if outcomes[0] != outcomes[1] {
+		t.Error("clones of the same seed should fight their battles alike", outcomes)
+	}
+	if source.Int63() != rand.New(rand.NewSource(7)).Int63() {
+		t.Error("the battles of the clones should not draw from the source of the original")
+	}
+}
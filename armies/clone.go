@@ -0,0 +1,62 @@
+package armies
+
+import (
+	"math/rand"
+
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/diplomats"
+	"github.com/pgruenbacher/got/economy"
+	"github.com/pgruenbacher/got/regions"
+)
+
+/*
+Clone copies the manager onto a copy of the world it acts on, the armies are
+pointed at the given regions and characters. A resolver drawing random numbers
+is given a source of its own from the seed, so that battles fought by the clone
+leave the source of the original untouched, and clones of the same seed fight
+them alike.
+*/
+func (self ArmiesManager) Clone(r regions.Regions, d *diplomats.DiplomatsTable, c characters.Characters, t economy.Treasuries, seed int64) ArmiesManager {
+	clone := self
+	clone.regions, clone.diplomacy, clone.characters, clone.treasuries = r, d, c, t
+	clone.Armies = make(Armies, len(self.Armies))
+	copyArmies(clone.Armies, self.Armies)
+	for _, army := range clone.Armies {
+		clone.rebind(army)
+	}
+	clone.musters = nil
+	for _, m := range self.musters {
+		army := newArmy(m.army)
+		clone.rebind(army)
+		clone.musters = append(clone.musters, &muster{army: army, turns: m.turns})
+	}
+	clone.supports = append([]SupportOrder(nil), self.supports...)
+	clone.ambushes = append([]AmbushOrder(nil), self.ambushes...)
+	clone.intercepts = append([]InterceptOrder(nil), self.intercepts...)
+	clone.conditionals = append([]ConditionalOrder(nil), self.conditionals...)
+	if resolver, err := self.combatResolver(); err == nil {
+		if seeded, ok := resolver.(SeededResolver); ok {
+			clone.resolver = seeded.Seeded(rand.New(rand.NewSource(seed)))
+		}
+	}
+	return clone
+}
+
+// clone copies the regions, characters and treasuries for the manager alone, resolving
+// orders never changes diplomacy so the table is shared.
+func (self ArmiesManager) clone(seed int64) ArmiesManager {
+	return self.Clone(self.regions.Clone(), self.diplomacy, self.characters.Clone(), self.treasuries.Clone(), seed)
+}
+
+// rebind points the copied army at the regions and commander of the copied world
+func (self ArmiesManager) rebind(army *Army) {
+	if army.Region != nil {
+		army.Region = self.regions[army.Region.Id]
+	}
+	if army.Home != nil {
+		army.Home = self.regions[army.Home.Id]
+	}
+	if army.commander != nil {
+		army.commander = self.characters[army.commander.Id]
+	}
+}
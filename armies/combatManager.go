@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"math/rand"
 	"time"
+
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/regions"
 )
 
 type battle struct {
@@ -40,6 +43,13 @@ func (self *Army) setInCombat() {
 	}
 }
 
+// EndBattles clears the combat state of the armies once the turn is over.
+func (self *ArmiesManager) EndBattles() {
+	for _, army := range self.Armies {
+		army.combatState = 0
+	}
+}
+
 func (self Army) inCombat() bool {
 	if self.combatState == 0 {
 		return false
@@ -68,89 +78,123 @@ const (
 	DESTROYED CombatContext = "DESTRUCTION"
 	DEFEATED  CombatContext = "DEFEATED"
 	DRAW      CombatContext = "DRAW"
+	// the army gave up the field as its stance demanded
+	RETREATED CombatContext = "RETREATED"
 )
 
-type combatEvent struct {
+type CombatEvent struct {
 	TargetArmy armyId
 	ByArmy     armyId
 	Ctx        CombatContext
+	// fate of the target army's commander, if it fell or was captured
+	Commander *characters.CharacterEvent
+	// experience gained by the armies that survived
+	Veterancy []ExperienceEvent
+	// breakdown of the modifiers each side fought with
+	TargetModifiers Modifiers
+	ByModifiers     Modifiers
+	// the armies wiped out in the battle
+	Destroyed []DestructionEvent
+	fate      *fate
+	// the boundary fought across, worn down once the results stand
+	boundary regions.Boundary
+}
+
+func (self *CombatEvent) explain(army1 *Army, modifiers1, modifiers2 Modifiers) {
+	if self.TargetArmy == army1.Id {
+		self.TargetModifiers, self.ByModifiers = modifiers1, modifiers2
+	} else {
+		self.TargetModifiers, self.ByModifiers = modifiers2, modifiers1
+	}
 }
 
 func init() {
 	rand.Seed(time.Now().UTC().UnixNano())
 }
 
-func (self ArmiesManager) resolveBattles(battles battles) (events []combatEvent, err error) {
+func (self ArmiesManager) resolveBattles(battles battles) (events []CombatEvent, support []SupportEvent, err error) {
+	resolver, err := self.combatResolver()
+	if err != nil {
+		return events, support, err
+	}
 	tmpAttackMap := make(map[armyId]armyId)
-	// set the combat mofidifiers which will be accumulated
-	var bonus1, bonus2 CombatModifier
+	fought := make(map[armyId]*Army)
 	// validation section, and mapping attacks
 	for _, battle := range battles {
 		if _, ok := tmpAttackMap[battle.army1.Id]; !ok {
 			// army 1 is attackign army 2
 			tmpAttackMap[battle.army1.Id] = battle.army2.Id
+			fought[battle.army1.Id] = battle.army1
+			fought[battle.army2.Id] = battle.army2
 		} else {
-			return events, errors.New(fmt.Sprintf("there cannot be multiple attack battles for army %v", battle.army1.Id))
+			return events, support, errors.New(fmt.Sprintf("there cannot be multiple attack battles for army %v", battle.army1.Id))
 		}
 	}
+	attacked := attackedArmies(battles)
+	support = self.cutSupports(attacked)
 
 	for _, battle := range battles {
-		// if army1 is attacking army2
-		if battle.army1.defending() && !battle.army2.defending() {
-			// army 2 is attacking defending army 1
-			bonus1 = bonus1 + self.defenseBonus(battle.army1)
-			bonus2 = bonus2 + CombatModifier(battle.army2.Region.Edges[battle.army1.Region.Id].Boundary.AttackPenalty())
-			event := inflictDamages(battle.army1, battle.army2, bonus1, bonus2)
-			events = append(events, event)
-			// add defense bonus
-
-		} else if !battle.army1.defending() && battle.army2.defending() {
-			// army 1 is attacking defended army 2
-			bonus1 = bonus1 + CombatModifier(battle.army1.Region.Edges[battle.army2.Region.Id].Boundary.AttackPenalty())
-			bonus2 = bonus2 + self.defenseBonus(battle.army2)
-			event := inflictDamages(battle.army1, battle.army2, bonus1, bonus2)
-			events = append(events, event)
-			// add defense bonuis
-
-		} else if attackingEachother(tmpAttackMap, battle) {
-			// armies 1 and 2 are attacking eachother, need to cancel the second battle
-			event := inflictDamages(battle.army1, battle.army2, bonus1, bonus2)
-			events = append(events, event)
-
-		} else if battle.ctx == SURPRISE_ATTACK {
-			// else army 1 has been surprised by army 2, use the SUPRISE ATTACK MODIFIER on army 1
-			bonus1 = bonus1 + self.Config.ConstantModifiers[SURPRISE_ATTACK]
-			event := inflictDamages(battle.army1, battle.army2, bonus1, bonus2)
-			events = append(events, event)
-			// perform combat
+		e, ok := engage(tmpAttackMap, battle)
+		if !ok {
+			continue
+		}
+		var given []SupportEvent
+		e.supporters, given = self.supportsFor(e, attacked)
+		support = append(support, given...)
+		// modifiers are gathered afresh for every battle
+		modifiers1 := self.modifiers(e, battle.army1)
+		modifiers2 := self.modifiers(e, battle.army2)
+		size1, size2 := battle.army1.Size, battle.army2.Size
+		event := resolver.Resolve(battle.army1, battle.army2, modifiers1.Total(), modifiers2.Total())
+		event = self.voluntaryRetreat(event, battle.army1, battle.army2, size1, size2)
+		event.explain(battle.army1, modifiers1, modifiers2)
+		if e.defender != nil {
+			if edge, ok := e.attacker().Region.Edges[e.defender.Region.Id]; ok {
+				event.boundary = edge.Boundary
+			}
 		}
+		events = append(events, event)
+	}
+	for i, event := range events {
+		events[i] = commanderFate(event, fought[event.TargetArmy], fought[event.ByArmy], sourceOf(resolver))
+		events[i].Veterancy = self.gainExperience(event, fought[event.TargetArmy], fought[event.ByArmy])
+	}
+	// losses are spread over the units of each army
+	for _, army := range fought {
+		army.syncComposition()
+	}
+	return events, support, nil
+}
+
+// settleBattles carries out what was decided in the battles once their results
+// stand, the armies left without men are removed
+func (self *ArmiesManager) settleBattles(combats []CombatEvent) {
+	settleFates(combats)
+	for i, combat := range combats {
+		damageBoundary(combat.boundary)
+		for _, id := range []armyId{combat.TargetArmy, combat.ByArmy} {
+			if army, ok := self.Armies[id]; ok && army.Size <= 0 {
+				combats[i].Destroyed = append(combats[i].Destroyed, self.destroy(id))
+			}
+		}
+	}
+}
+
+// walls attacked across are worn down by the assault
+func damageBoundary(b regions.Boundary) {
+	if d, ok := b.(regions.Destructible); ok {
+		d.Damage(1)
 	}
-	return events, nil
 }
 
 func attackingEachother(tmpMap map[armyId]armyId, battle battle) bool {
 	return tmpMap[battle.army1.Id] == battle.army2.Id && tmpMap[battle.army2.Id] == battle.army1.Id
 }
 
-// weights can be terrain penalties, army size differences, etc. Must be great
-// army quality stays constant during battles
-func inflictDamages(army1, army2 *Army, weight1, weight2 CombatModifier) combatEvent {
-	// damage from each army is a product of the army size and quality. therefore quality enhances the initial damage value
-	inflict1 := army1.Size * army1.Quality
-	inflict2 := army2.Size * army2.Quality
-	// a reasonable fraction is taken, to improve pacing of battles to last
-	// a random modifier is included
-	randSeed1 := rand.Float32()
-	randSeed2 := rand.Float32()
-	// random infliction algorithm! so that 3-4 battles between similar sized adversaries will likely result in one destruction
-	inflict1 = inflict1/6 + int(float32(inflict1)/6*randSeed1)
-	inflict2 = inflict2/6 + int(float32(inflict2)/6*randSeed2)
-
-	// apply the weights
-	inflict1 = inflict1 + int(CombatModifier(inflict1)*weight1)
-	inflict2 = inflict2 + int(CombatModifier(inflict2)*weight2)
-
-	// now inflict the damages upon each army size, relative to their quality
+// applyDamages inflicts the damages of one round upon each army size, relative to
+// their quality. army quality stays constant during battles, experience is gained
+// once they are over
+func applyDamages(army1, army2 *Army, inflict1, inflict2 int) CombatEvent {
 	// compare the damage of the battle, this will result in a victor and loser of the round
 	// morale will drop for the loser. morale can't be regained during battles.
 	// if morale drops to zero, then return a routing event.
@@ -202,11 +246,11 @@ func inflictDamages(army1, army2 *Army, weight1, weight2 CombatModifier) combatE
 
 }
 
-func newCombatEvent(armyId1, armyId2 armyId, ctx CombatContext) combatEvent {
-	return combatEvent{
-		armyId1,
-		armyId2,
-		ctx,
+func newCombatEvent(armyId1, armyId2 armyId, ctx CombatContext) CombatEvent {
+	return CombatEvent{
+		TargetArmy: armyId1,
+		ByArmy:     armyId2,
+		Ctx:        ctx,
 	}
 }
 
@@ -0,0 +1,117 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/families"
+)
+
+// InitCharacters resolves the commanders named in the army config.
+func (self *ArmiesManager) InitCharacters(c characters.Characters) error {
+	self.characters = c
+	for _, army := range self.Armies {
+		if army.Commander == "" {
+			continue
+		}
+		if err := self.AssignCommander(army.Id, army.Commander); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AssignCommander puts a member of the army's house in command of it,
+// a character may only command one army at a time.
+func (self *ArmiesManager) AssignCommander(id armyId, characterId characters.CharacterId) error {
+	army, ok := self.Armies[id]
+	if !ok {
+		return errors.New(fmt.Sprintf("invalid army id %v", id))
+	}
+	character, ok := self.characters[characterId]
+	if !ok {
+		return errors.New(fmt.Sprintf("army %v commander %v does not exist", id, characterId))
+	}
+	if character.House != army.House {
+		return errors.New(fmt.Sprintf("commander %v is not a member of house %v", characterId, army.House))
+	}
+	if !character.Available() {
+		return characters.CharacterUnavailable
+	}
+	for _, other := range self.Armies {
+		if other.Id != army.Id && other.commander == character {
+			return errors.New(fmt.Sprintf("commander %v already commands army %v", characterId, other.Id))
+		}
+	}
+	army.Commander = characterId
+	army.commander = character
+	return nil
+}
+
+func (self Army) hasCommander() bool {
+	return self.commander != nil && self.commander.Available()
+}
+
+func (self ArmiesManager) commanderBonus(army *Army) CombatModifier {
+	if !army.hasCommander() {
+		return 0
+	}
+	return self.Config.CommanderModifier * CombatModifier(army.commander.Skill-1)
+}
+
+// fate of a commander, decided in battle and carried out once the results of the battle stand
+type fate struct {
+	commander *characters.Character
+	killed    bool
+	by        families.HouseId
+}
+
+// commanderFate decides whether the commander of the beaten army falls or is taken prisoner,
+// drawing from the source of the resolver.
+func commanderFate(event CombatEvent, target, by *Army, r *rand.Rand) CombatEvent {
+	if !target.hasCommander() {
+		return event
+	}
+	var killed, captured float32
+	switch event.Ctx {
+	case DESTROYED:
+		killed, captured = 0.3, 0.4
+	case ROUTED:
+		killed, captured = 0.1, 0.25
+	case DEFEATED:
+		killed, captured = 0.05, 0
+	default:
+		return event
+	}
+	if target.commander.HasTrait(characters.CAUTIOUS) {
+		killed, captured = killed/2, captured/2
+	}
+	if target.commander.HasTrait(characters.BRAVE) {
+		killed = killed * 2
+	}
+	roll := randFloat(r)
+	if roll < killed {
+		event.fate = &fate{target.commander, true, by.House}
+	} else if roll < killed+captured {
+		event.fate = &fate{target.commander, false, by.House}
+	}
+	return event
+}
+
+// settleFates kills or captures the commanders whose fate was decided in the battles
+func settleFates(combats []CombatEvent) {
+	for i, combat := range combats {
+		if combat.fate == nil {
+			continue
+		}
+		var e characters.CharacterEvent
+		if combat.fate.killed {
+			e = combat.fate.commander.Kill(combat.fate.by)
+		} else {
+			e = combat.fate.commander.Capture(combat.fate.by)
+		}
+		combats[i].Commander = &e
+	}
+}
@@ -0,0 +1,223 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pgruenbacher/got/families"
+	"github.com/pgruenbacher/got/regions"
+)
+
+type ConditionKind string
+
+const (
+	// the army is attacked by enemies of greater total strength
+	ATTACKED_BY ConditionKind = "ATTACKED_BY"
+	// an enemy army marches into the region
+	ENEMY_ENTERS ConditionKind = "ENEMY_ENTERS"
+	// the allied army leaves its region
+	ALLY_MOVES ConditionKind = "ALLY_MOVES"
+)
+
+type Condition struct {
+	Kind     ConditionKind
+	Region   regions.RegionId
+	Army     armyId
+	Strength int
+}
+
+/*
+ConditionalOrder holds the army in place until the condition is met during the
+resolution of the turn, and the march is then made. Unless inverts the
+condition, the march being made only if it is not met.
+*/
+type ConditionalOrder struct {
+	ArmyOrder
+	If     Condition
+	Unless bool
+	Then   MarchOrder
+}
+
+// all the engine knows of the turn so far when conditions are evaluated
+type resolution struct {
+	armies  Armies
+	events  []MarchEvent
+	battles battles
+}
+
+// a house sees the regions its armies stand in, and those next to them
+func observes(a Armies, house families.HouseId, region regions.RegionId) bool {
+	for _, army := range a {
+		if army.House != house {
+			continue
+		}
+		if army.Region.Id == region {
+			return true
+		}
+		if _, ok := army.Region.Edges[region]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (self resolution) observes(house families.HouseId, region regions.RegionId) bool {
+	return observes(self.armies, house, region)
+}
+
+// armies that actually left their region this turn
+func (self resolution) moved(id armyId) (MarchEvent, bool) {
+	for _, e := range self.events {
+		if e.ArmyId == id && (e.Ctx == MARCH || e.Ctx == ATTACK_PURSUIT) {
+			return e, true
+		}
+	}
+	return MarchEvent{}, false
+}
+
+func (self resolution) marches() (orders []MarchOrder) {
+	for _, e := range self.events {
+		if e.Ctx == MARCH || e.Ctx == ATTACK_PURSUIT {
+			orders = append(orders, newMarchOrder(e.ArmyId, e.Src, e.Dst, e.Ctx))
+		}
+	}
+	return orders
+}
+
+// the enemies attacking the army, surprising it included
+func (self resolution) attackers(army *Army) (attackers []*Army) {
+	for _, b := range self.battles {
+		if b.army2.Id == army.Id {
+			attackers = append(attackers, b.army1)
+		} else if b.ctx == SURPRISE_ATTACK && b.army1.Id == army.Id {
+			attackers = append(attackers, b.army2)
+		}
+	}
+	return attackers
+}
+
+func (self ArmiesManager) holds(c Condition, army *Army, r resolution) bool {
+	switch c.Kind {
+	case ATTACKED_BY:
+		strength := 0
+		for _, attacker := range r.attackers(army) {
+			strength = strength + attacker.Strength()
+		}
+		return strength > c.Strength
+	case ENEMY_ENTERS:
+		if !r.observes(army.House, c.Region) {
+			return false
+		}
+		for _, enemy := range armiesWithin(r.armies, self.regions[c.Region]) {
+			if _, ok := r.moved(enemy.Id); ok && self.diplomacy.IsEnemy(army.House, enemy.House) {
+				return true
+			}
+		}
+		return false
+	case ALLY_MOVES:
+		if ally, ok := r.armies[c.Army]; !ok || !r.observes(army.House, ally.Region.Id) {
+			return false
+		}
+		_, ok := r.moved(c.Army)
+		return ok
+	}
+	return false
+}
+
+func (self *ArmiesManager) conditionalOrders(orders []ConditionalOrder) (e []MarchEvent, err error) {
+	if err = self.validateConditionalOrders(orders); err != nil {
+		return e, err
+	}
+	for _, order := range orders {
+		self.conditionals = append(self.conditionals, order)
+		e = append(e, newConditionEvent(order, self.Armies[order.ArmyId], CONDITIONAL))
+	}
+	return e, nil
+}
+
+// triggered evaluates the standing conditional orders once the marches are made.
+// An army that retreats slips away from the battles it was drawn into.
+func (self *ArmiesManager) triggered(r resolution) (orders []MarchOrder, combats battles, e []MarchEvent) {
+	combats = r.battles
+	for _, order := range self.conditionals {
+		army, ok := r.armies[order.ArmyId]
+		if !ok {
+			continue
+		}
+		if self.holds(order.If, army, r) == order.Unless {
+			e = append(e, newConditionEvent(order, army, HELD))
+			continue
+		}
+		e = append(e, newConditionEvent(order, army, TRIGGERED))
+		orders = append(orders, order.Then)
+		if order.Then.Ctx != RETREAT {
+			continue
+		}
+		remaining := combats[:0:0]
+		for _, b := range combats {
+			if b.army2 != army && !(b.ctx == SURPRISE_ATTACK && b.army1 == army) {
+				remaining = append(remaining, b)
+			}
+		}
+		combats = remaining
+		army.combatState = 0
+	}
+	return orders, combats, e
+}
+
+// the conditions of an order are only disclosed to its house
+func newConditionEvent(order ConditionalOrder, army *Army, ctx Context) MarchEvent {
+	e := newMarchEvent(order.ArmyId, order.Then.Src, order.Then.Dst, ctx)
+	condition := order.If
+	e.Condition = &condition
+	e.Restrict(army.commandingHouses()...)
+	return e
+}
+
+func (self *ArmiesManager) withdrawConditionals(orders []MarchOrder) {
+	conditionals := self.conditionals[:0]
+	for _, conditional := range self.conditionals {
+		if !marching(orders, conditional.ArmyId) {
+			conditionals = append(conditionals, conditional)
+		}
+	}
+	self.conditionals = conditionals
+}
+
+func (self *ArmiesManager) validateConditionalOrders(orders []ConditionalOrder) error {
+	ordered := make(map[armyId]bool)
+	for _, order := range self.conditionals {
+		ordered[order.ArmyId] = true
+	}
+	for _, order := range orders {
+		army, ok := self.Armies[order.ArmyId]
+		if !ok {
+			return errors.New(fmt.Sprintf("order %v had invalid armyId %v", order.Id, order.ArmyId))
+		}
+		if order.Then.ArmyId != army.Id {
+			return errors.New(fmt.Sprintf("order %v can only march army %v", order.Id, army.Id))
+		}
+		if ordered[army.Id] {
+			return errors.New(fmt.Sprintf("army %v already has a conditional order", army.Id))
+		}
+		ordered[army.Id] = true
+		if err := self.validateMarchOrders([]MarchOrder{order.Then}); err != nil {
+			return err
+		}
+		switch order.If.Kind {
+		case ATTACKED_BY:
+		case ENEMY_ENTERS:
+			if _, ok := self.regions[order.If.Region]; !ok {
+				return errors.New(fmt.Sprintf("invalid condition region %v", order.If.Region))
+			}
+		case ALLY_MOVES:
+			ally, ok := self.Armies[order.If.Army]
+			if !ok || ally.Id == army.Id || !self.diplomacy.IsAlly(army.House, ally.House) {
+				return errors.New(fmt.Sprintf("army %v is not an ally of army %v", order.If.Army, army.Id))
+			}
+		default:
+			return errors.New(fmt.Sprintf("unknown condition %v", order.If.Kind))
+		}
+	}
+	return nil
+}
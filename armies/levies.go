@@ -0,0 +1,107 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/pgruenbacher/got/families"
+)
+
+// a vassal's army has been called to (or released from) its liege's service
+type LevyEvent struct {
+	ArmyEvent
+	Liege    families.HouseId
+	Released bool
+}
+
+type armiesBySize []*Army
+
+func (a armiesBySize) Len() int      { return len(a) }
+func (a armiesBySize) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a armiesBySize) Less(i, j int) bool {
+	if a[i].Size == a[j].Size {
+		return a[i].Id < a[j].Id
+	}
+	return a[i].Size > a[j].Size
+}
+
+// CanCommand is true for the house's own armies and the levies it has called from its vassals.
+func (self *ArmiesManager) CanCommand(houseId families.HouseId, id armyId) bool {
+	army, ok := self.Armies[id]
+	if !ok {
+		return false
+	}
+	return army.House == houseId || army.LeviedBy == houseId
+}
+
+// the houses the army's events are disclosed to, its liege as well while levied
+func (self Army) commandingHouses() []string {
+	if self.LeviedBy == "" {
+		return []string{string(self.House)}
+	}
+	return []string{string(self.House), string(self.LeviedBy)}
+}
+
+// CallLevies places armies of each direct vassal under the liege's command,
+// largest first, until the vassal's levy obligation is met.
+func (self *ArmiesManager) CallLevies(liege families.HouseId) (events []LevyEvent, err error) {
+	houses := self.diplomacy.Houses()
+	if _, ok := houses[liege]; !ok {
+		return events, errors.New(fmt.Sprintf("liege %v does not exist", liege))
+	}
+	for _, vassal := range houses.Vassals(liege) {
+		var own armiesBySize
+		total, levied := 0, 0
+		for _, army := range self.Armies {
+			if army.House != vassal.Id {
+				continue
+			}
+			total += army.Size
+			if army.LeviedBy == liege {
+				levied += army.Size
+			} else if army.LeviedBy == "" {
+				own = append(own, army)
+			}
+		}
+		owed := int(vassal.Levy * float32(total))
+		sort.Sort(own)
+		for _, army := range own {
+			if levied >= owed {
+				break
+			}
+			army.LeviedBy = liege
+			levied += army.Size
+			events = append(events, newLevyEvent(army.Id, liege, false))
+		}
+	}
+	return events, nil
+}
+
+// ReleaseLevies returns every levied army of the vassal to its own command.
+func (self *ArmiesManager) ReleaseLevies(vassal families.HouseId) (events []LevyEvent) {
+	for _, army := range self.Armies {
+		if army.House == vassal && army.LeviedBy != "" {
+			events = append(events, newLevyEvent(army.Id, army.LeviedBy, true))
+			army.LeviedBy = ""
+		}
+	}
+	return events
+}
+
+// Rebel breaks the vassal from its liege, its levies return home to fight against their former liege.
+func (self *ArmiesManager) Rebel(vassal families.HouseId) (families.RebellionEvent, []LevyEvent, error) {
+	e, err := self.diplomacy.Rebel(vassal)
+	if err != nil {
+		return e, nil, err
+	}
+	return e, self.ReleaseLevies(vassal), nil
+}
+
+func newLevyEvent(id armyId, liege families.HouseId, released bool) LevyEvent {
+	return LevyEvent{
+		ArmyEvent: newArmyEvent(id),
+		Liege:     liege,
+		Released:  released,
+	}
+}
@@ -0,0 +1,192 @@
+package armies
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pgruenbacher/got/regions"
+)
+
+type ModifierSource string
+
+const (
+	TERRAIN_MODIFIER     ModifierSource = "TERRAIN"
+	BOUNDARY_MODIFIER    ModifierSource = "BOUNDARY"
+	CONTEXT_MODIFIER     ModifierSource = "CONTEXT"
+	COMMANDER_MODIFIER   ModifierSource = "COMMANDER"
+	COMPOSITION_MODIFIER ModifierSource = "COMPOSITION"
+	SUPPORT_MODIFIER     ModifierSource = "SUPPORT"
+	STANCE_MODIFIER      ModifierSource = "STANCE"
+)
+
+// a single contribution to the strength of an army in battle, kept so the
+// outcome can be explained to the players afterwards
+type Modifier struct {
+	Source ModifierSource
+	Reason string
+	Value  CombatModifier
+}
+
+type Modifiers []Modifier
+
+func (self Modifiers) Total() (total CombatModifier) {
+	for _, m := range self {
+		total = total + m.Value
+	}
+	return total
+}
+
+// how the two armies met, which decides the modifiers that apply to each side
+type engagement struct {
+	battle
+	field    *regions.Region
+	boundary regions.Boundary
+	// the army holding a defended position, if any
+	defender *Army
+	// the army caught unprepared, if any
+	surprised *Army
+	// the army attacked while on the march, if any
+	caught *Army
+	// armies lending their strength to either side
+	supporters map[armyId][]*Army
+}
+
+func (self engagement) attacker() *Army {
+	if self.defender == self.army1 {
+		return self.army2
+	}
+	return self.army1
+}
+
+func (self engagement) enemyOf(army *Army) *Army {
+	if army == self.army1 {
+		return self.army2
+	}
+	return self.army1
+}
+
+// engage returns false if the armies never actually come to blows
+func engage(attacks map[armyId]armyId, b battle) (e engagement, ok bool) {
+	e.battle = b
+	e.field, e.boundary = battleground(b)
+	if b.army1.defending() && !b.army2.defending() {
+		// army 2 is attacking defending army 1
+		e.defender = b.army1
+	} else if !b.army1.defending() && b.army2.defending() {
+		// army 1 is attacking defended army 2
+		e.defender = b.army2
+	} else if attackingEachother(attacks, b) {
+		// armies 1 and 2 are attacking eachother, no one has the advantage
+	} else if b.ctx == SURPRISE_ATTACK {
+		// army 1 has been surprised by army 2
+		e.surprised = b.army1
+	} else if b.ctx == CAUGHT_ATTACK || b.ctx == ATTACK_PURSUIT || b.ctx == INTERCEPT {
+		// army 2 is attacked while marching
+		e.caught = b.army2
+	} else if b.ctx == REDIRECT_ATTACK {
+		// army 1 turned its attack upon the new position of army 2
+	} else {
+		return e, false
+	}
+	return e, true
+}
+
+// each source contributes at most one modifier per army, and every battle
+// runs through the whole pipeline afresh
+type modifierSource func(self ArmiesManager, e engagement, army *Army) (Modifier, bool)
+
+var modifierPipeline = []modifierSource{
+	terrainSource,
+	boundarySource,
+	contextSource,
+	commanderSource,
+	compositionSource,
+	supportSource,
+	stanceSource,
+}
+
+func (self ArmiesManager) modifiers(e engagement, army *Army) (m Modifiers) {
+	for _, source := range modifierPipeline {
+		if modifier, ok := source(self, e, army); ok && modifier.Value != 0 {
+			m = append(m, modifier)
+		}
+	}
+	return m
+}
+
+func terrainSource(self ArmiesManager, e engagement, army *Army) (Modifier, bool) {
+	if e.defender != army {
+		return Modifier{}, false
+	}
+	return Modifier{
+		Source: TERRAIN_MODIFIER,
+		Reason: fmt.Sprintf("defending in %v terrain", army.Region.Terrain),
+		Value:  self.defenseBonus(army),
+	}, true
+}
+
+// the attacker of a defended position is hampered by the boundary it crosses,
+// and so is an army intercepting another
+func boundarySource(self ArmiesManager, e engagement, army *Army) (Modifier, bool) {
+	var target *regions.Region
+	if e.defender != nil && e.attacker() == army {
+		target = e.defender.Region
+	} else if e.ctx == INTERCEPT && e.army1 == army {
+		target = e.army2.Region
+	} else {
+		return Modifier{}, false
+	}
+	edge, ok := army.Region.Edges[target.Id]
+	if !ok {
+		return Modifier{}, false
+	}
+	return Modifier{
+		Source: BOUNDARY_MODIFIER,
+		Reason: fmt.Sprintf("attacking across %v", edge.Boundary.Kind()),
+		Value:  CombatModifier(edge.Boundary.AttackPenalty()),
+	}, true
+}
+
+// the way the armies met favours one side or the other
+func contextSource(self ArmiesManager, e engagement, army *Army) (Modifier, bool) {
+	var ctx Context
+	var reason string
+	switch {
+	case e.surprised == army:
+		ctx, reason = SURPRISE_ATTACK, fmt.Sprintf("caught by surprise by %v", e.enemyOf(army).Id)
+	case e.caught == army:
+		ctx, reason = CAUGHT_ATTACK, fmt.Sprintf("attacked on the march by %v", e.enemyOf(army).Id)
+	case e.army1 == army && e.ctx == ATTACK_PURSUIT:
+		ctx, reason = ATTACK_PURSUIT, fmt.Sprintf("pursuing %v", e.enemyOf(army).Id)
+	case e.army1 == army && e.ctx == REDIRECT_ATTACK:
+		ctx, reason = REDIRECT_ATTACK, fmt.Sprintf("attack redirected against %v", e.enemyOf(army).Id)
+	case e.army1 == army && e.ctx == INTERCEPT:
+		ctx, reason = INTERCEPT, fmt.Sprintf("intercepting %v", e.enemyOf(army).Id)
+	default:
+		return Modifier{}, false
+	}
+	return Modifier{
+		Source: CONTEXT_MODIFIER,
+		Reason: reason,
+		Value:  self.Config.ConstantModifiers[ctx],
+	}, true
+}
+
+func commanderSource(self ArmiesManager, e engagement, army *Army) (Modifier, bool) {
+	if !army.hasCommander() {
+		return Modifier{}, false
+	}
+	return Modifier{
+		Source: COMMANDER_MODIFIER,
+		Reason: fmt.Sprintf("commanded by %v (skill %v)", army.commander.Name, army.commander.Skill),
+		Value:  self.commanderBonus(army),
+	}, true
+}
+
+func compositionSource(self ArmiesManager, e engagement, army *Army) (Modifier, bool) {
+	return Modifier{
+		Source: COMPOSITION_MODIFIER,
+		Reason: "fighting with " + strings.Join(army.Composition.types(), ", "),
+		Value:  self.compositionBonus(army, e.field, e.boundary, army == e.army2),
+	}, true
+}
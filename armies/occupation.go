@@ -0,0 +1,53 @@
+package armies
+
+import (
+	"sort"
+
+	"github.com/pgruenbacher/got/families"
+	"github.com/pgruenbacher/got/regions"
+)
+
+// EvalOccupation lets armies holding a region unopposed take control of it.
+// Armies at war with the owner occupy it, and the owner's side liberates it.
+// Unclaimed regions are claimed by whoever holds them. A castle that still
+// holds must be besieged first.
+func (self *ArmiesManager) EvalOccupation() (events []regions.OwnerEvent) {
+	for _, region := range self.regions {
+		occupier, ok := self.holder(armiesWithin(self.Armies, region))
+		if !ok {
+			continue
+		}
+		switch {
+		case region.Owner == "":
+			events = append(events, region.Occupy(occupier, self.Config.OccupationTurns)...)
+		case self.diplomacy.IsAlly(occupier, region.Owner):
+			events = append(events, region.Liberate()...)
+		case region.Castle.Holds():
+			// the region can't be taken while its castle holds out
+		case self.diplomacy.IsEnemy(occupier, region.Owner):
+			events = append(events, region.Occupy(occupier, self.Config.OccupationTurns)...)
+		}
+	}
+	return events
+}
+
+// holder is the house of the largest army present, if the armies present are not contesting the region.
+func (self *ArmiesManager) holder(present []*Army) (families.HouseId, bool) {
+	var held armiesBySize
+	for _, army := range present {
+		if army.House == "" {
+			continue
+		}
+		for _, other := range held {
+			if !self.diplomacy.IsAlly(army.House, other.House) {
+				return "", false
+			}
+		}
+		held = append(held, army)
+	}
+	if len(held) == 0 {
+		return "", false
+	}
+	sort.Sort(held)
+	return held[0].House, true
+}
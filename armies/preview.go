@@ -0,0 +1,243 @@
+package armies
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
+)
+
+// Orders are the orders a house gives its armies for the turn.
+type Orders struct {
+	Stance      []StanceOrder
+	Support     []SupportOrder
+	Ambush      []AmbushOrder
+	Intercept   []InterceptOrder
+	Conditional []ConditionalOrder
+	Recruit     []RecruitOrder
+	Merge       []MergeOrder
+	Split       []SplitOrder
+	March       []MarchOrder
+	Assault     []AssaultOrder
+}
+
+// Preview is what the orders of a house are expected to bring about.
+type Preview struct {
+	Events   []events.EventsInterface
+	Battles  []CombatEvent
+	Warnings []string
+}
+
+type orderGroup struct {
+	kind   string
+	orders interface{}
+}
+
+/*
+Preview resolves the turn on a copy of the world with only the orders of the
+house. The house knows nothing of what other houses ordered, so the enemies it
+can see are taken to hold their ground and those it can't are left out.
+Orders that could not be given are returned as warnings. The battles are
+fought with the seed, so a preview may be repeated.
+*/
+func (self ArmiesManager) Preview(house families.HouseId, orders Orders, seed int64) (p Preview) {
+	world := self.clone(seed)
+	world.hideFrom(house)
+	return world.Command(house, orders)
+}
+
+// Command gives the orders of the house to the armies it commands, and reports
+// what they brought about. Orders it can't give are left out with a warning.
+func (self *ArmiesManager) Command(house families.HouseId, orders Orders) (p Preview) {
+	orders, p.Warnings = self.restrict(house, orders)
+	before := []orderGroup{
+		{"stance", orders.Stance},
+		{"support", orders.Support},
+		{"ambush", orders.Ambush},
+		{"intercept", orders.Intercept},
+		{"conditional", orders.Conditional},
+		{"recruit", orders.Recruit},
+		{"merge", orders.Merge},
+		{"split", orders.Split},
+	}
+	for _, group := range before {
+		p.read(self, group)
+	}
+	if len(orders.March) > 0 {
+		for _, v := range self.ValidateMarchOrders(orders.March) {
+			for _, w := range v.Warnings() {
+				p.Warnings = append(p.Warnings, w.String())
+			}
+		}
+		marches, combats, support, err := self.resolveMarches(orders.March)
+		if err != nil {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("march orders: %v", err))
+		}
+		p.Events = appendEvents(p.Events, marches)
+		p.Events = appendEvents(p.Events, support)
+		p.Battles = combats
+	}
+	p.read(self, orderGroup{"assault", orders.Assault})
+	p.Events = events.Visible(string(house), p.Events)
+	return p
+}
+
+func (self *Preview) read(world *ArmiesManager, group orderGroup) {
+	if reflect.ValueOf(group.orders).Len() == 0 {
+		return
+	}
+	e, err := world.ReadOrders(group.orders)
+	if err != nil {
+		self.Warnings = append(self.Warnings, fmt.Sprintf("%v orders: %v", group.kind, err))
+		return
+	}
+	self.Events = appendEvents(self.Events, e)
+}
+
+// the orders return their events as slices of their own type
+func appendEvents(e []events.EventsInterface, slice interface{}) []events.EventsInterface {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice {
+		return e
+	}
+	for i := 0; i < v.Len(); i++ {
+		e = append(e, v.Index(i).Interface())
+	}
+	return e
+}
+
+// hideFrom leaves the house only the armies within its sight, and none of the
+// standing orders or musters of other houses
+func (self *ArmiesManager) hideFrom(house families.HouseId) {
+	supports := self.supports[:0]
+	for _, order := range self.supports {
+		if self.CanCommand(house, order.ArmyId) {
+			supports = append(supports, order)
+		}
+	}
+	self.supports = supports
+	self.ambushes, self.intercepts = self.HiddenOrders(house)
+	conditionals := self.conditionals[:0]
+	for _, order := range self.conditionals {
+		if self.CanCommand(house, order.ArmyId) {
+			conditionals = append(conditionals, order)
+		}
+	}
+	self.conditionals = conditionals
+	musters := self.musters[:0]
+	for _, m := range self.musters {
+		if m.army.House == house {
+			musters = append(musters, m)
+		}
+	}
+	self.musters = musters
+	for id, army := range self.Armies {
+		if !self.CanCommand(house, id) && !observes(self.Armies, house, army.Region.Id) {
+			delete(self.Armies, id)
+		}
+	}
+}
+
+// foreign explains why the house can't order the army, empty if it can
+func (self ArmiesManager) foreign(house families.HouseId, id armyId) string {
+	army, ok := self.Armies[id]
+	if !ok {
+		return fmt.Sprintf("house %v has no army %v in sight", house, id)
+	}
+	if !self.CanCommand(house, id) {
+		return fmt.Sprintf("army %v of house %v is not under the command of %v", id, army.House, house)
+	}
+	return ""
+}
+
+// restrict drops the orders the house can't give, with a warning for each
+func (self ArmiesManager) restrict(house families.HouseId, o Orders) (r Orders, warnings []string) {
+	for _, order := range o.Stance {
+		if w := self.foreign(house, order.ArmyId); w != "" {
+			warnings = append(warnings, w)
+			continue
+		}
+		r.Stance = append(r.Stance, order)
+	}
+	for _, order := range o.Support {
+		if w := self.foreign(house, order.ArmyId); w != "" {
+			warnings = append(warnings, w)
+			continue
+		}
+		r.Support = append(r.Support, order)
+	}
+	for _, order := range o.Ambush {
+		if w := self.foreign(house, order.ArmyId); w != "" {
+			warnings = append(warnings, w)
+			continue
+		}
+		r.Ambush = append(r.Ambush, order)
+	}
+	for _, order := range o.Intercept {
+		if w := self.foreign(house, order.ArmyId); w != "" {
+			warnings = append(warnings, w)
+			continue
+		}
+		r.Intercept = append(r.Intercept, order)
+	}
+	for _, order := range o.Conditional {
+		if w := self.foreign(house, order.ArmyId); w != "" {
+			warnings = append(warnings, w)
+			continue
+		}
+		r.Conditional = append(r.Conditional, order)
+	}
+	for _, order := range o.Recruit {
+		if order.House != house {
+			warnings = append(warnings, fmt.Sprintf("house %v can't recruit for house %v", house, order.House))
+			continue
+		}
+		r.Recruit = append(r.Recruit, order)
+	}
+merges:
+	for _, order := range o.Merge {
+		for _, id := range append([]armyId{order.ArmyId}, order.Others...) {
+			if w := self.foreign(house, id); w != "" {
+				warnings = append(warnings, w)
+				continue merges
+			}
+		}
+		r.Merge = append(r.Merge, order)
+	}
+	for _, order := range o.Split {
+		if w := self.foreign(house, order.ArmyId); w != "" {
+			warnings = append(warnings, w)
+			continue
+		}
+		r.Split = append(r.Split, order)
+	}
+	for _, order := range o.March {
+		if w := self.foreign(house, order.ArmyId); w != "" {
+			warnings = append(warnings, w)
+			continue
+		}
+		r.March = append(r.March, order)
+	}
+	for _, order := range o.Assault {
+		if w := self.foreign(house, order.ArmyId); w != "" {
+			warnings = append(warnings, w)
+			continue
+		}
+		r.Assault = append(r.Assault, order)
+	}
+	return r, warnings
+}
+
+var ExampleOrders = `
+	[[Stance]]
+	ArmyId = "army1"
+	Stance = "AGGRESSIVE"
+
+	[[March]]
+	ArmyId = "army1"
+	Src = "region3cost"
+	Dst = "region2cost"
+	Ctx = "MARCH"
+
+	`
@@ -0,0 +1,85 @@
+package armies
+
+type RecoveryRates struct {
+	// morale regained per turn of rest, and extra at home
+	Morale     int
+	HomeMorale int
+	MaxMorale  int
+	// men drawn from the house manpower per turn, and extra at home
+	Reinforcements     int
+	HomeReinforcements int
+}
+
+// morale and men regained by a resting army
+type RecoveryEvent struct {
+	ArmyEvent
+	Morale         int
+	Reinforcements int
+	// experience and quality after the fresh men joined
+	Veterancy *ExperienceEvent
+}
+
+// resting armies must be supplied, out of combat, and in a region their house or its allies hold
+func (self *ArmiesManager) resting(army *Army) bool {
+	if army.House == "" || army.inCombat() || army.SupplyState != "" {
+		return false
+	}
+	if self.enemyWithin(army, armiesWithin(self.Armies, army.Region)) != nil {
+		return false
+	}
+	region := army.Region
+	if region.Owner == "" || region.Owner != region.Controller {
+		return false
+	}
+	return self.diplomacy.IsAlly(army.House, region.Owner)
+}
+
+// EvalRecovery restores morale to resting armies, and reinforces them from the
+// manpower of their house. Commanders rally their armies faster.
+func (self *ArmiesManager) EvalRecovery() (events []RecoveryEvent) {
+	rates := self.Config.Recovery
+	for _, army := range self.Armies {
+		if !self.resting(army) {
+			continue
+		}
+		e := RecoveryEvent{ArmyEvent: newArmyEvent(army.Id)}
+		atHome := army.Region == army.Home
+		morale := rates.Morale
+		reinforcements := rates.Reinforcements
+		if atHome {
+			morale = morale + rates.HomeMorale
+			reinforcements = reinforcements + rates.HomeReinforcements
+		}
+		if army.hasCommander() {
+			morale = morale + army.commander.Rally()
+		}
+		if army.Morale+morale > rates.MaxMorale {
+			morale = rates.MaxMorale - army.Morale
+		}
+		if morale > 0 {
+			army.Morale = army.Morale + morale
+			e.Morale = morale
+		}
+		if army.Size+reinforcements > 100 {
+			reinforcements = 100 - army.Size
+		}
+		if self.treasuries != nil {
+			treasury := self.treasuries.Of(army.House)
+			if reinforcements > treasury.Manpower {
+				reinforcements = treasury.Manpower
+			}
+			if reinforcements > 0 {
+				treasury.Manpower = treasury.Manpower - reinforcements
+				army.Size = army.Size + reinforcements
+				army.syncComposition()
+				e.Reinforcements = reinforcements
+				veterancy := self.dilute(army, reinforcements)
+				e.Veterancy = &veterancy
+			}
+		}
+		if e.Morale > 0 || e.Reinforcements > 0 {
+			events = append(events, e)
+		}
+	}
+	return events
+}
@@ -0,0 +1,181 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gopkg.in/validator.v2"
+
+	"github.com/pgruenbacher/got/actions"
+	"github.com/pgruenbacher/got/economy"
+	"github.com/pgruenbacher/got/families"
+	"github.com/pgruenbacher/got/regions"
+)
+
+type RecruitmentRates struct {
+	// gold per unit of size and quality
+	Gold float32
+	// recruits raised per point of the region's manpower yield
+	SizePerManpower int
+	// fresh recruits are never better than this
+	MaxQuality int
+	Morale     int
+	// turns before the new army takes the field
+	MusterTurns int
+}
+
+// raise a new army in a region owned by the house
+type RecruitOrder struct {
+	actions.Order
+	House   families.HouseId
+	Region  regions.RegionId
+	Size    int
+	Quality int
+}
+
+type RecruitContext string
+
+const (
+	MUSTERING RecruitContext = "MUSTERING"
+	MUSTERED  RecruitContext = "MUSTERED"
+)
+
+type RecruitEvent struct {
+	ArmyEvent
+	House  families.HouseId
+	Region regions.RegionId
+	Ctx    RecruitContext
+	Cost   economy.Resources
+}
+
+// an army being raised, it joins the armies once its turns are up
+type muster struct {
+	army  *Army
+	turns int
+}
+
+// InitEconomy gives the manager the treasuries recruitment is paid from.
+func (self *ArmiesManager) InitEconomy(t economy.Treasuries) {
+	self.treasuries = t
+}
+
+func (self ArmiesManager) recruitCost(size, quality int) economy.Resources {
+	return economy.Resources{
+		Gold:     int(math.Ceil(float64(self.Config.Recruitment.Gold * float32(size*quality)))),
+		Manpower: size,
+	}
+}
+
+func (self *ArmiesManager) recruitOrders(orders []RecruitOrder) (e []RecruitEvent, err error) {
+	if err = self.validateRecruitOrders(orders); err != nil {
+		return e, err
+	}
+	for _, order := range orders {
+		army := self.newRecruit(order)
+		army.Id = self.nextArmyId(order.House)
+		cost := self.recruitCost(order.Size, order.Quality)
+		treasury := self.treasuries.Of(order.House)
+		*treasury = treasury.Sub(cost)
+		self.musters = append(self.musters, &muster{
+			army:  army,
+			turns: self.Config.Recruitment.MusterTurns,
+		})
+		e = append(e, newRecruitEvent(army, MUSTERING, cost))
+	}
+	return e, nil
+}
+
+// the army an order raises, numbered once it is mustered
+func (self *ArmiesManager) newRecruit(order RecruitOrder) *Army {
+	region := self.regions[order.Region]
+	return &Army{
+		Morale:         self.Config.Recruitment.Morale,
+		Size:           order.Size,
+		Quality:        order.Quality,
+		StartingRegion: region.Id,
+		HomeRegion:     region.Id,
+		Region:         region,
+		Home:           region,
+		House:          order.House,
+	}
+}
+
+// EvalMusters brings the armies whose muster is complete into the field.
+func (self *ArmiesManager) EvalMusters() (e []RecruitEvent) {
+	mustering := self.musters[:0]
+	for _, m := range self.musters {
+		if m.turns > 0 {
+			m.turns--
+		}
+		if m.turns > 0 {
+			mustering = append(mustering, m)
+			continue
+		}
+		self.Armies[m.army.Id] = m.army
+		e = append(e, newRecruitEvent(m.army, MUSTERED, economy.Resources{}))
+	}
+	self.musters = mustering
+	return e
+}
+
+// new armies are numbered per house, skipping ids already in the field or mustering
+func (self *ArmiesManager) nextArmyId(houseId families.HouseId) armyId {
+	taken := make(map[armyId]bool, len(self.musters))
+	for _, m := range self.musters {
+		taken[m.army.Id] = true
+	}
+	for n := 1; ; n++ {
+		id := armyId(fmt.Sprintf("%v-army%d", houseId, n))
+		if _, ok := self.Armies[id]; !ok && !taken[id] {
+			return id
+		}
+	}
+}
+
+func (self *ArmiesManager) validateRecruitOrders(orders []RecruitOrder) error {
+	if self.treasuries == nil {
+		return errors.New("recruitment requires the treasuries of the houses")
+	}
+	raised := make(map[regions.RegionId]int)
+	// the orders of a house are paid together, none are raised unless all can be
+	costs := make(map[families.HouseId]economy.Resources)
+	for _, order := range orders {
+		region, ok := self.regions[order.Region]
+		if !ok {
+			return errors.New(fmt.Sprintf("invalid region id %v", order.Region))
+		}
+		if region.Owner != order.House || region.Controller != order.House {
+			return errors.New(fmt.Sprintf("house %v doesn't hold region %v", order.House, order.Region))
+		}
+		if region.Castle.Besieged() {
+			return errors.New(fmt.Sprintf("can't recruit in besieged region %v", order.Region))
+		}
+		raised[region.Id] = raised[region.Id] + order.Size
+		if raised[region.Id] > region.Yield.Manpower*self.Config.Recruitment.SizePerManpower {
+			return errors.New(fmt.Sprintf("region %v manpower can't support %v recruits", order.Region, raised[region.Id]))
+		}
+		if order.Quality > self.Config.Recruitment.MaxQuality {
+			return errors.New(fmt.Sprintf("recruits can't have quality above %v", self.Config.Recruitment.MaxQuality))
+		}
+		// same struct rules as the armies of the scenario
+		if err := validator.Validate(self.newRecruit(order)); err != nil {
+			return err
+		}
+		costs[order.House] = costs[order.House].Add(self.recruitCost(order.Size, order.Quality))
+		if !self.treasuries.Of(order.House).Covers(costs[order.House]) {
+			return errors.New(fmt.Sprintf("house %v can't afford to recruit %v men in %v", order.House, order.Size, order.Region))
+		}
+	}
+	return nil
+}
+
+func newRecruitEvent(army *Army, ctx RecruitContext, cost economy.Resources) RecruitEvent {
+	return RecruitEvent{
+		ArmyEvent: newArmyEvent(army.Id),
+		House:     army.House,
+		Region:    army.Region.Id,
+		Ctx:       ctx,
+		Cost:      cost,
+	}
+}
@@ -0,0 +1,187 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/validator.v2"
+)
+
+// the other armies are folded into the ordered army
+type MergeOrder struct {
+	ArmyOrder
+	Others []armyId
+}
+
+// the ordered army splits off a new army of the given size
+type SplitOrder struct {
+	ArmyOrder
+	Size int
+}
+
+type MergeEvent struct {
+	ArmyEvent
+	Merged     []armyId
+	Size       int
+	Morale     int
+	Quality    int
+	Experience int
+}
+
+type SplitEvent struct {
+	ArmyEvent
+	NewArmy armyId
+	Size    int
+}
+
+func (self *ArmiesManager) mergeOrders(orders []MergeOrder) (e []MergeEvent, err error) {
+	if err = self.validateMergeOrders(orders); err != nil {
+		return e, err
+	}
+	// every merged army is checked before any is merged, so that a refused order leaves all armies as they were
+	merges := make([]Army, len(orders))
+	for i, order := range orders {
+		merges[i] = self.merged(order)
+		if err = validator.Validate(merges[i]); err != nil {
+			return e, err
+		}
+	}
+	for i, order := range orders {
+		army := self.Armies[order.ArmyId]
+		*army = merges[i]
+		for _, id := range order.Others {
+			delete(self.Armies, id)
+		}
+		self.dropOrders(order.Others)
+		e = append(e, MergeEvent{
+			ArmyEvent:  newArmyEvent(army.Id),
+			Merged:     order.Others,
+			Size:       army.Size,
+			Morale:     army.Morale,
+			Quality:    army.Quality,
+			Experience: army.Experience,
+		})
+	}
+	return e, nil
+}
+
+// the ordered army with the others folded in
+func (self *ArmiesManager) merged(order MergeOrder) Army {
+	army := self.Armies[order.ArmyId]
+	merged := *army
+	morale, quality, experience := army.Morale*army.Size, army.Quality*army.Size, army.Experience*army.Size
+	for _, id := range order.Others {
+		other := self.Armies[id]
+		if len(merged.Composition) > 0 || len(other.Composition) > 0 {
+			merged.Composition = merged.composed().add(other.composed())
+		}
+		merged.Size = merged.Size + other.Size
+		morale = morale + other.Morale*other.Size
+		quality = quality + other.Quality*other.Size
+		experience = experience + other.Experience*other.Size
+	}
+	// morale, quality and experience are averaged over the men of each army
+	merged.Morale = roundedDiv(morale, merged.Size)
+	merged.Quality = roundedDiv(quality, merged.Size)
+	merged.Experience = experience / merged.Size
+	return merged
+}
+
+func (self *ArmiesManager) splitOrders(orders []SplitOrder) (e []SplitEvent, err error) {
+	if err = self.validateSplitOrders(orders); err != nil {
+		return e, err
+	}
+	for _, order := range orders {
+		army := self.Armies[order.ArmyId]
+		// the new army keeps the morale, quality and levy of the old, but not its commander
+		split := newArmy(army)
+		split.Id = self.nextArmyId(army.House)
+		split.Size = order.Size
+		split.Commander = ""
+		split.commander = nil
+		split.Composition = army.Composition.scaled(order.Size)
+		army.Size = army.Size - order.Size
+		if err = validator.Validate(army); err != nil {
+			army.Size = army.Size + order.Size
+			return e, err
+		}
+		if err = validator.Validate(split); err != nil {
+			army.Size = army.Size + order.Size
+			return e, err
+		}
+		army.Composition = remainingComposition(army.Composition, split.Composition)
+		self.Armies[split.Id] = split
+		e = append(e, SplitEvent{
+			ArmyEvent: newArmyEvent(army.Id),
+			NewArmy:   split.Id,
+			Size:      split.Size,
+		})
+	}
+	return e, nil
+}
+
+func (self *ArmiesManager) validateMergeOrders(orders []MergeOrder) error {
+	ordered := make(map[armyId]bool)
+	for _, order := range orders {
+		army, ok := self.Armies[order.ArmyId]
+		if !ok {
+			return errors.New(fmt.Sprintf("order %v had invalid armyId %v", order.Id, order.ArmyId))
+		}
+		if len(order.Others) == 0 {
+			return errors.New(fmt.Sprintf("order %v has no armies to merge", order.Id))
+		}
+		for _, id := range append([]armyId{order.ArmyId}, order.Others...) {
+			other, ok := self.Armies[id]
+			if !ok {
+				return errors.New(fmt.Sprintf("order %v had invalid armyId %v", order.Id, id))
+			}
+			if ordered[id] {
+				return errors.New(fmt.Sprintf("army %v can't be merged twice", id))
+			}
+			ordered[id] = true
+			if other.House != army.House || other.Region != army.Region {
+				return errors.New(fmt.Sprintf("army %v must be of house %v in region %v to merge", id, army.House, army.Region.Id))
+			}
+			if other.inCombat() {
+				return errors.New(fmt.Sprintf("army %v can't merge while in combat", id))
+			}
+		}
+	}
+	return nil
+}
+
+func (self *ArmiesManager) validateSplitOrders(orders []SplitOrder) error {
+	ordered := make(map[armyId]bool)
+	for _, order := range orders {
+		army, ok := self.Armies[order.ArmyId]
+		if !ok {
+			return errors.New(fmt.Sprintf("order %v had invalid armyId %v", order.Id, order.ArmyId))
+		}
+		if ordered[order.ArmyId] {
+			return errors.New(fmt.Sprintf("army %v can't be split twice", order.ArmyId))
+		}
+		ordered[order.ArmyId] = true
+		if order.Size <= 0 || order.Size >= army.Size {
+			return errors.New(fmt.Sprintf("army %v of size %v can't split off %v", army.Id, army.Size, order.Size))
+		}
+		if army.inCombat() {
+			return errors.New(fmt.Sprintf("army %v can't split while in combat", army.Id))
+		}
+	}
+	return nil
+}
+
+func remainingComposition(c, split Composition) Composition {
+	if len(c) == 0 {
+		return c
+	}
+	left := make(Composition, len(c))
+	for unitType, count := range c {
+		left[unitType] = count - split[unitType]
+	}
+	return left
+}
+
+func roundedDiv(a, b int) int {
+	return (a + b/2) / b
+}
@@ -0,0 +1,193 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+/*
+A CombatResolver decides the outcome of a single round of battle between two
+armies. Weights are the accumulated combat modifiers of each side, and the
+resolver is expected to reduce the armies it is given accordingly.
+*/
+type CombatResolver interface {
+	Resolve(army1, army2 *Army, weight1, weight2 CombatModifier) CombatEvent
+}
+
+const (
+	STANDARD_RESOLVER   = "STANDARD"
+	LANCHESTER_RESOLVER = "LANCHESTER"
+	DICE_RESOLVER       = "DICE"
+)
+
+// the resolver used for battles is picked by name from the scenario config,
+// the remaining values tune the alternative models
+type ResolverConfig struct {
+	Name string
+	// fraction of its strength an army inflicts every round, lanchester model
+	Attrition float32 `validate:"min=0,max=1"`
+	// soldiers per die, lowest roll of a d6 that hits for quality 1 troops and the
+	// damage of each hit, dice model
+	DicePer   int `validate:"min=0"`
+	HitOn     int `validate:"min=0,max=7"`
+	HitDamage int `validate:"min=0"`
+}
+
+var resolvers = map[string]func(ResolverConfig) CombatResolver{
+	STANDARD_RESOLVER: func(c ResolverConfig) CombatResolver {
+		return StandardResolver{}
+	},
+	LANCHESTER_RESOLVER: func(c ResolverConfig) CombatResolver {
+		return LanchesterResolver{Attrition: c.Attrition}
+	},
+	DICE_RESOLVER: func(c ResolverConfig) CombatResolver {
+		return DiceResolver{DicePer: c.DicePer, HitOn: c.HitOn, HitDamage: c.HitDamage}
+	},
+}
+
+// RegisterResolver makes a custom resolver selectable from the scenario config
+func RegisterResolver(name string, f func(ResolverConfig) CombatResolver) {
+	resolvers[name] = f
+}
+
+func NewCombatResolver(c ResolverConfig) (CombatResolver, error) {
+	if c.Name == "" {
+		c.Name = STANDARD_RESOLVER
+	}
+	f, ok := resolvers[c.Name]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("unknown combat resolver %v", c.Name))
+	}
+	return f(c), nil
+}
+
+// SetCombatResolver overrides the resolver named in the config
+func (self *ArmiesManager) SetCombatResolver(r CombatResolver) {
+	self.resolver = r
+}
+
+func (self ArmiesManager) combatResolver() (CombatResolver, error) {
+	if self.resolver != nil {
+		return self.resolver, nil
+	}
+	return NewCombatResolver(self.Config.Resolver)
+}
+
+// weights can be terrain penalties, army size differences, etc.
+func weighted(inflict int, weight CombatModifier) int {
+	return inflict + int(CombatModifier(inflict)*weight)
+}
+
+/*
+The standard model: damage from each army is a product of the army size and
+quality, a reasonable fraction of which is taken along with a random modifier.
+Rand may be set for reproducible battles, the shared source is used otherwise.
+*/
+type StandardResolver struct {
+	Rand *rand.Rand
+}
+
+func (self StandardResolver) Resolve(army1, army2 *Army, weight1, weight2 CombatModifier) CombatEvent {
+	// quality enhances the initial damage value
+	inflict1 := army1.Size * army1.Quality
+	inflict2 := army2.Size * army2.Quality
+	// random infliction algorithm! so that 3-4 battles between similar sized adversaries will likely result in one destruction
+	inflict1 = inflict1/6 + int(float32(inflict1)/6*randFloat(self.Rand))
+	inflict2 = inflict2/6 + int(float32(inflict2)/6*randFloat(self.Rand))
+	return applyDamages(army1, army2, weighted(inflict1, weight1), weighted(inflict2, weight2))
+}
+
+/*
+A deterministic model after Lanchester's square law: every round each army
+loses in proportion to the strength of its opponent, so concentrating a larger
+force pays off more than linearly.
+*/
+type LanchesterResolver struct {
+	Attrition float32
+}
+
+func (self LanchesterResolver) Resolve(army1, army2 *Army, weight1, weight2 CombatModifier) CombatEvent {
+	attrition := self.Attrition
+	if attrition == 0 {
+		attrition = 0.25
+	}
+	inflict1 := int(float32(army1.Size*army1.Quality) * attrition)
+	inflict2 := int(float32(army2.Size*army2.Quality) * attrition)
+	return applyDamages(army1, army2, weighted(inflict1, weight1), weighted(inflict2, weight2))
+}
+
+/*
+A table top model: every army rolls a d6 per DicePer soldiers, better troops
+hitting on lower rolls. Each hit inflicts HitDamage times the quality of the army.
+*/
+type DiceResolver struct {
+	DicePer   int
+	HitOn     int
+	HitDamage int
+	Rand      *rand.Rand
+}
+
+func (self DiceResolver) Resolve(army1, army2 *Army, weight1, weight2 CombatModifier) CombatEvent {
+	inflict1 := self.roll(army1)
+	inflict2 := self.roll(army2)
+	return applyDamages(army1, army2, weighted(inflict1, weight1), weighted(inflict2, weight2))
+}
+
+func (self DiceResolver) roll(army *Army) (inflict int) {
+	per, hitOn, damage := self.DicePer, self.HitOn, self.HitDamage
+	if per == 0 {
+		per = 5
+	}
+	if hitOn == 0 {
+		hitOn = 6
+	}
+	if damage == 0 {
+		damage = 2
+	}
+	dice := army.Size / per
+	if dice == 0 {
+		dice = 1
+	}
+	for i := 0; i < dice; i++ {
+		if randIntn(self.Rand, 6)+army.Quality >= hitOn {
+			inflict += damage * army.Quality
+		}
+	}
+	return inflict
+}
+
+// resolvers drawing from a random source of their own
+type randomResolver interface {
+	source() *rand.Rand
+}
+
+func (self StandardResolver) source() *rand.Rand {
+	return self.Rand
+}
+
+func (self DiceResolver) source() *rand.Rand {
+	return self.Rand
+}
+
+// sourceOf the resolver, nil for the shared source
+func sourceOf(resolver CombatResolver) *rand.Rand {
+	if r, ok := resolver.(randomResolver); ok {
+		return r.source()
+	}
+	return nil
+}
+
+func randFloat(r *rand.Rand) float32 {
+	if r == nil {
+		return rand.Float32()
+	}
+	return r.Float32()
+}
+
+func randIntn(r *rand.Rand, n int) int {
+	if r == nil {
+		return rand.Intn(n)
+	}
+	return r.Intn(n)
+}
@@ -0,0 +1,198 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/diplomats"
+	"github.com/pgruenbacher/got/economy"
+	"github.com/pgruenbacher/got/regions"
+)
+
+/*
+ManagerState is the manager laid out for saving, the armies with the ids of
+their regions in place of the regions themselves. The combat resolver is not
+saved, it is built again from the config.
+*/
+type ManagerState struct {
+	Armies       []ArmyState
+	Musters      []MusterState
+	Supports     []SupportOrder
+	Ambushes     []AmbushOrder
+	Intercepts   []InterceptOrder
+	Conditionals []ConditionalOrder
+	Config       Config
+}
+
+type ArmyState struct {
+	*Army
+	Region      regions.RegionId
+	Home        regions.RegionId
+	CombatState combatStatus
+}
+
+type MusterState struct {
+	ArmyState
+	Turns int
+}
+
+// combat phases are saved by name, so that renumbering them doesn't break saved games
+var combatPhases = map[combatStatus]string{
+	COMBAT_PHASE1: "COMBAT_PHASE1",
+	COMBAT_PHASE2: "COMBAT_PHASE2",
+}
+
+func (self combatStatus) MarshalText() ([]byte, error) {
+	if self == 0 {
+		return []byte{}, nil
+	}
+	name, ok := combatPhases[self]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("unknown combat phase %d", int(self)))
+	}
+	return []byte(name), nil
+}
+
+func (self *combatStatus) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*self = 0
+		return nil
+	}
+	for phase, name := range combatPhases {
+		if name == string(text) {
+			*self = phase
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("unknown combat phase %v", string(text)))
+}
+
+func newArmyState(army *Army) ArmyState {
+	s := ArmyState{Army: army, CombatState: army.combatState}
+	if army.Region != nil {
+		s.Region = army.Region.Id
+	}
+	if army.Home != nil {
+		s.Home = army.Home.Id
+	}
+	return s
+}
+
+func (self ArmiesManager) Save() (s ManagerState) {
+	var ids []string
+	for id := range self.Armies {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		s.Armies = append(s.Armies, newArmyState(self.Armies[armyId(id)]))
+	}
+	for _, m := range self.musters {
+		s.Musters = append(s.Musters, MusterState{newArmyState(m.army), m.turns})
+	}
+	s.Supports = self.supports
+	s.Ambushes = self.ambushes
+	s.Intercepts = self.intercepts
+	s.Conditionals = self.conditionals
+	s.Config = self.Config
+	return s
+}
+
+// Restore rebuilds the manager from the save on the restored world, the armies
+// pointed at its regions and their commanders.
+func (self *ArmiesManager) Restore(s ManagerState, r regions.Regions, d *diplomats.DiplomatsTable, c characters.Characters, t economy.Treasuries) error {
+	self.regions, self.diplomacy, self.characters, self.treasuries = r, d, c, t
+	self.Config = s.Config
+	self.resolver = nil
+	self.Armies = make(Armies, len(s.Armies))
+	for _, state := range s.Armies {
+		army, err := self.restoreArmy(state)
+		if err != nil {
+			return err
+		}
+		if _, ok := self.Armies[army.Id]; ok {
+			return errors.New(fmt.Sprintf("army %v saved twice", army.Id))
+		}
+		self.Armies[army.Id] = army
+	}
+	self.musters = nil
+	for _, state := range s.Musters {
+		army, err := self.restoreArmy(state.ArmyState)
+		if err != nil {
+			return err
+		}
+		self.musters = append(self.musters, &muster{army: army, turns: state.Turns})
+	}
+	self.supports = s.Supports
+	self.ambushes = s.Ambushes
+	self.intercepts = s.Intercepts
+	self.conditionals = s.Conditionals
+	return self.Check()
+}
+
+func (self ArmiesManager) restoreArmy(s ArmyState) (*Army, error) {
+	if s.Army == nil {
+		return nil, errors.New("army saved without its state")
+	}
+	army := s.Army
+	region, ok := self.regions[s.Region]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("army %v region %v does not exist", army.Id, s.Region))
+	}
+	home, ok := self.regions[s.Home]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("army %v home region %v does not exist", army.Id, s.Home))
+	}
+	army.Region, army.Home, army.combatState = region, home, s.CombatState
+	if army.Commander != "" {
+		commander, ok := self.characters[army.Commander]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("army %v commander %v does not exist", army.Id, army.Commander))
+		}
+		army.commander = commander
+	}
+	return army, nil
+}
+
+// Check makes sure the armies stand in the regions of the world, and that the
+// standing orders are given to armies that exist.
+func (self ArmiesManager) Check() error {
+	for id, army := range self.Armies {
+		if army.Id != id {
+			return errors.New(fmt.Sprintf("army %v saved as %v", army.Id, id))
+		}
+		if army.Region == nil || army.Region != self.regions[army.Region.Id] {
+			return errors.New(fmt.Sprintf("army %v stands outside the regions", id))
+		}
+		if army.Home == nil || army.Home != self.regions[army.Home.Id] {
+			return errors.New(fmt.Sprintf("army %v home is outside the regions", id))
+		}
+		if army.Commander != "" && (army.commander == nil || army.commander != self.characters[army.Commander]) {
+			return errors.New(fmt.Sprintf("army %v commander %v is not among the characters", id, army.Commander))
+		}
+	}
+	if err := self.validateCompositions(); err != nil {
+		return err
+	}
+	var ordered []armyId
+	for _, order := range self.supports {
+		ordered = append(ordered, order.ArmyId, order.Supported)
+	}
+	for _, order := range self.ambushes {
+		ordered = append(ordered, order.ArmyId)
+	}
+	for _, order := range self.intercepts {
+		ordered = append(ordered, order.ArmyId)
+	}
+	for _, order := range self.conditionals {
+		ordered = append(ordered, order.ArmyId)
+	}
+	for _, id := range ordered {
+		if _, ok := self.Armies[id]; !ok {
+			return errors.New(fmt.Sprintf("standing order for army %v which does not exist", id))
+		}
+	}
+	return nil
+}
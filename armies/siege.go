@@ -0,0 +1,167 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
+	"github.com/pgruenbacher/got/regions"
+)
+
+type SiegeContext string
+
+const (
+	SIEGE_BEGUN   SiegeContext = "SIEGE_BEGUN"
+	SIEGE_LIFTED  SiegeContext = "SIEGE_LIFTED"
+	STARVING      SiegeContext = "STARVING"
+	ASSAULTED     SiegeContext = "ASSAULTED"
+	CASTLE_FALLEN SiegeContext = "CASTLE_FALLEN"
+)
+
+// assault the castle of the region the army is besieging
+type AssaultOrder struct {
+	ArmyOrder
+	Region regions.RegionId
+}
+
+type SiegeEvent struct {
+	events.Event
+	RegionId regions.RegionId
+	Besieger families.HouseId
+	Ctx      SiegeContext
+	Garrison int
+	Walls    int
+	// the region changing hands when the castle falls
+	Ownership []regions.OwnerEvent
+	// the assaulting army, if it was wiped out
+	Destroyed *DestructionEvent
+}
+
+// EvalSieges begins a siege wherever enemies hold a fortified region unopposed,
+// starves out garrisons that run out of provisions, and lifts sieges once the
+// besiegers are gone or driven off by a relief army.
+func (self *ArmiesManager) EvalSieges() (events []SiegeEvent) {
+	for _, region := range self.regions {
+		castle := region.Castle
+		if !castle.Holds() {
+			continue
+		}
+		besieger, ok := self.holder(armiesWithin(self.Armies, region))
+		if !ok || !self.diplomacy.IsEnemy(besieger, region.Owner) {
+			if castle.Besieged() {
+				events = append(events, newSiegeEvent(region, castle.Besieger, SIEGE_LIFTED))
+				castle.Besieger = ""
+				castle.SiegeTurns = 0
+			}
+			continue
+		}
+		if !castle.Besieged() {
+			castle.Besieger = besieger
+			castle.SiegeTurns = 0
+			events = append(events, newSiegeEvent(region, besieger, SIEGE_BEGUN))
+			continue
+		}
+		castle.SiegeTurns++
+		if castle.SiegeTurns <= castle.Provisions {
+			continue
+		}
+		// provisions are exhausted, the garrison starves
+		starved := castle.Garrison / 4
+		if starved < 1 {
+			starved = 1
+		}
+		castle.Garrison = castle.Garrison - starved
+		events = append(events, self.castleStatus(region, STARVING))
+	}
+	return events
+}
+
+func (self *ArmiesManager) assaultOrders(orders []AssaultOrder) (e []SiegeEvent, err error) {
+	if err = self.validateAssaultOrders(orders); err != nil {
+		return e, err
+	}
+	resolver, err := self.combatResolver()
+	if err != nil {
+		return e, err
+	}
+	for _, order := range orders {
+		army, ok := self.Armies[order.ArmyId]
+		region := self.regions[order.Region]
+		castle := region.Castle
+		if !ok || !castle.Holds() {
+			// army wiped out or castle already fallen in an earlier assault this turn
+			continue
+		}
+		garrison := newGarrison(region)
+		resolver.Resolve(army, garrison, self.commanderBonus(army)+self.assaultBonus(army)+self.Config.ConstantModifiers[ASSAULT], CombatModifier(castle.DefenseBonus()))
+		army.syncComposition()
+		castle.Garrison = garrison.Size
+		castle.Damage(1)
+		event := self.castleStatus(region, ASSAULTED)
+		if army.Size <= 0 {
+			destroyed := self.destroy(army.Id)
+			event.Destroyed = &destroyed
+		}
+		e = append(e, event)
+	}
+	return e, nil
+}
+
+// once the garrison is gone the castle falls, and the besieger takes control of the region
+func (self *ArmiesManager) castleStatus(region *regions.Region, ctx SiegeContext) SiegeEvent {
+	castle := region.Castle
+	if castle.Garrison > 0 {
+		return newSiegeEvent(region, castle.Besieger, ctx)
+	}
+	castle.Garrison = 0
+	e := newSiegeEvent(region, castle.Besieger, CASTLE_FALLEN)
+	e.Ownership = region.Occupy(castle.Besieger, self.Config.OccupationTurns)
+	castle.Besieger = ""
+	castle.SiegeTurns = 0
+	return e
+}
+
+func (self *ArmiesManager) validateAssaultOrders(orders []AssaultOrder) error {
+	for _, order := range orders {
+		army, ok := self.Armies[order.ArmyId]
+		if !ok {
+			return errors.New(fmt.Sprintf("order %v had invalid armyId %v", order.Id, order.ArmyId))
+		}
+		region, ok := self.regions[order.Region]
+		if !ok {
+			return errors.New(fmt.Sprintf("invalid region id %v", order.Region))
+		}
+		if army.Region != region {
+			return errors.New(fmt.Sprintf("army %v is not located at %v", army.Id, region.Id))
+		}
+		if !region.Castle.Besieged() || !self.diplomacy.IsAlly(army.House, region.Castle.Besieger) {
+			return errors.New(fmt.Sprintf("army %v is not besieging region %v", army.Id, region.Id))
+		}
+	}
+	return nil
+}
+
+// the garrison fights as an army of the region's owner
+func newGarrison(region *regions.Region) *Army {
+	return &Army{
+		Id:      armyId(fmt.Sprintf("%v-garrison", region.Id)),
+		Morale:  5,
+		Size:    region.Castle.Garrison,
+		Quality: 3,
+		Region:  region,
+		Home:    region,
+		House:   region.Owner,
+	}
+}
+
+func newSiegeEvent(region *regions.Region, besieger families.HouseId, ctx SiegeContext) SiegeEvent {
+	return SiegeEvent{
+		Event:    events.NewEvent(),
+		RegionId: region.Id,
+		Besieger: besieger,
+		Ctx:      ctx,
+		Garrison: region.Castle.Garrison,
+		Walls:    region.Castle.Walls,
+	}
+}
@@ -0,0 +1,174 @@
+package armies
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/pgruenbacher/got/regions"
+)
+
+var NoSimulationRuns = errors.New("simulation needs at least one run")
+
+/*
+Scenario describes a single battle to be simulated: the attacker crosses the
+boundary, if any, into the region of the defender.
+*/
+type Scenario struct {
+	Attacker Army `toml:"attacker"`
+	Defender Army `toml:"defender"`
+	// terrain of the region being attacked
+	Terrain regions.Terrain `toml:"terrain"`
+	Ctx     Context         `toml:"context"`
+	River   *regions.River  `toml:"river"`
+	Wall    *regions.Wall   `toml:"wall"`
+	Runs    int             `toml:"runs"`
+	Seed    int64           `toml:"seed"`
+}
+
+// resolvers drawing random numbers can be given their own source, so that
+// simulations may be repeated
+type SeededResolver interface {
+	CombatResolver
+	Seeded(r *rand.Rand) CombatResolver
+}
+
+func (self StandardResolver) Seeded(r *rand.Rand) CombatResolver {
+	self.Rand = r
+	return self
+}
+
+func (self DiceResolver) Seeded(r *rand.Rand) CombatResolver {
+	self.Rand = r
+	return self
+}
+
+// Odds are the share of runs ending in each outcome for either side, a DRAW
+// counting for both, along with the size each side is expected to lose.
+type Odds struct {
+	Runs           int
+	Attacker       map[CombatContext]float32
+	Defender       map[CombatContext]float32
+	AttackerLosses float32
+	DefenderLosses float32
+}
+
+// Simulate fights the battle of the scenario over and over, with the resolver
+// and modifiers of the config, leaving the armies of the scenario untouched.
+func (self ArmiesManager) Simulate(s Scenario) (odds Odds, err error) {
+	if s.Runs <= 0 {
+		return odds, NoSimulationRuns
+	}
+	resolver, err := self.combatResolver()
+	if err != nil {
+		return odds, err
+	}
+	if seeded, ok := resolver.(SeededResolver); ok {
+		resolver = seeded.Seeded(rand.New(rand.NewSource(s.Seed)))
+	}
+	if s.Attacker.Id == "" {
+		s.Attacker.Id = "attacker"
+	}
+	if s.Defender.Id == "" {
+		s.Defender.Id = "defender"
+	}
+	if s.Attacker.Id == s.Defender.Id {
+		return odds, errors.New("armies of the scenario must have different ids")
+	}
+	field, from := s.battlefield()
+	odds = Odds{
+		Runs:     s.Runs,
+		Attacker: make(map[CombatContext]float32),
+		Defender: make(map[CombatContext]float32),
+	}
+	for i := 0; i < s.Runs; i++ {
+		attacker, defender := s.Attacker, s.Defender
+		attacker.Region, defender.Region = from, field
+		self.simulatedCommander(&attacker)
+		self.simulatedCommander(&defender)
+		b := newBattle(&attacker, &defender, s.Ctx)
+		// armies not taken by surprise meet the attack head on
+		attacks := map[armyId]armyId{attacker.Id: defender.Id, defender.Id: attacker.Id}
+		if s.Ctx == SURPRISE_ATTACK {
+			b = newBattle(&defender, &attacker, s.Ctx)
+			attacks = map[armyId]armyId{defender.Id: attacker.Id}
+		}
+		e, ok := engage(attacks, b)
+		if !ok {
+			return odds, errors.New("armies of the scenario never come to blows")
+		}
+		size1, size2 := b.army1.Size, b.army2.Size
+		event := resolver.Resolve(b.army1, b.army2, self.modifiers(e, b.army1).Total(), self.modifiers(e, b.army2).Total())
+		event = self.voluntaryRetreat(event, b.army1, b.army2, size1, size2)
+		if event.Ctx == DRAW {
+			odds.Attacker[DRAW]++
+			odds.Defender[DRAW]++
+		} else if event.TargetArmy == attacker.Id {
+			odds.Attacker[event.Ctx]++
+		} else {
+			odds.Defender[event.Ctx]++
+		}
+		odds.AttackerLosses = odds.AttackerLosses + float32(s.Attacker.Size-attacker.Size)
+		odds.DefenderLosses = odds.DefenderLosses + float32(s.Defender.Size-defender.Size)
+	}
+	for ctx := range odds.Attacker {
+		odds.Attacker[ctx] = odds.Attacker[ctx] / float32(s.Runs)
+	}
+	for ctx := range odds.Defender {
+		odds.Defender[ctx] = odds.Defender[ctx] / float32(s.Runs)
+	}
+	odds.AttackerLosses = odds.AttackerLosses / float32(s.Runs)
+	odds.DefenderLosses = odds.DefenderLosses / float32(s.Runs)
+	return odds, nil
+}
+
+// two regions joined by the boundary of the scenario
+func (self Scenario) battlefield() (field, from *regions.Region) {
+	terrain := self.Terrain
+	if terrain == "" {
+		terrain = regions.Plain
+	}
+	field = &regions.Region{Id: "field", Terrain: terrain, Edges: make(map[regions.RegionId]*regions.Edge)}
+	from = &regions.Region{Id: "from", Terrain: terrain, Edges: make(map[regions.RegionId]*regions.Edge)}
+	var boundary regions.Boundary = new(regions.NoBoundary)
+	if self.River != nil {
+		boundary = self.River
+	} else if self.Wall != nil {
+		boundary = self.Wall
+	}
+	from.Edges[field.Id] = &regions.Edge{Src: from, Dst: field, Boundary: boundary}
+	field.Edges[from.Id] = &regions.Edge{Src: field, Dst: from, Boundary: boundary}
+	return field, from
+}
+
+// commanders named in a scenario are looked up among the known characters
+func (self ArmiesManager) simulatedCommander(army *Army) {
+	if army.commander != nil || army.Commander == "" {
+		return
+	}
+	if character, ok := self.characters[army.Commander]; ok {
+		army.commander = character
+	}
+}
+
+var ExampleScenario string = `
+	terrain = "HILL"
+	context = "ATTACK"
+	runs = 1000
+	seed = 1
+	[attacker]
+	Id = "attacker"
+	Size = 40
+	Quality = 3
+	Morale = 3
+	[attacker.composition]
+	INFANTRY = 30
+	CAVALRY = 10
+	[defender]
+	Id = "defender"
+	Size = 30
+	Quality = 3
+	Morale = 3
+	DefenseState = 1
+	[river]
+	attack_penalty = -0.2
+`
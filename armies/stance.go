@@ -0,0 +1,134 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Stance string
+
+const (
+	AGGRESSIVE Stance = "AGGRESSIVE"
+	BALANCED   Stance = "BALANCED"
+	CAUTIOUS   Stance = "CAUTIOUS"
+)
+
+// how an army of the stance fights, and when it gives up the field
+type StanceProfile struct {
+	// the army withdraws once its morale falls to this
+	RetreatMorale int
+	// or once its size falls below this share of what it started the battle with
+	RetreatSize float32 `validate:"min=0,max=1"`
+	// multipliers of the damage the army deals and takes, 1 if unset
+	Dealt float32 `validate:"min=0"`
+	Taken float32 `validate:"min=0"`
+	// whether the army follows an enemy that marched away from its attack
+	Pursues bool
+}
+
+type StanceOrder struct {
+	ArmyOrder
+	Stance Stance
+}
+
+type StanceEvent struct {
+	ArmyEvent
+	From Stance
+	To   Stance
+}
+
+// armies without a stance are balanced
+func (self Army) stance() Stance {
+	if self.Stance == "" {
+		return BALANCED
+	}
+	return self.Stance
+}
+
+func (self Stance) valid() bool {
+	switch self {
+	case "", AGGRESSIVE, BALANCED, CAUTIOUS:
+		return true
+	}
+	return false
+}
+
+func (self ArmiesManager) stanceProfile(army *Army) StanceProfile {
+	profile := self.Config.Stances[army.stance()]
+	if profile.Dealt == 0 {
+		profile.Dealt = 1
+	}
+	if profile.Taken == 0 {
+		profile.Taken = 1
+	}
+	return profile
+}
+
+// an army with no profile configured follows its enemy as it always has
+func (self ArmiesManager) pursues(army *Army) bool {
+	profile, ok := self.Config.Stances[army.stance()]
+	return !ok || profile.Pursues
+}
+
+func (self ArmiesManager) withdraws(army *Army, startingSize int) bool {
+	if army.Size <= 0 || army.Morale <= 0 {
+		return false
+	}
+	profile := self.stanceProfile(army)
+	return army.Morale <= profile.RetreatMorale || float32(army.Size) < profile.RetreatSize*float32(startingSize)
+}
+
+// voluntaryRetreat lets the loser of the round, or either side of a draw, give up
+// the field once its stance says the battle is lost
+func (self ArmiesManager) voluntaryRetreat(event CombatEvent, army1, army2 *Army, size1, size2 int) CombatEvent {
+	sizes := map[armyId]int{army1.Id: size1, army2.Id: size2}
+	switch event.Ctx {
+	case DEFEATED:
+		target := army1
+		if event.TargetArmy == army2.Id {
+			target = army2
+		}
+		if self.withdraws(target, sizes[target.Id]) {
+			event.Ctx = RETREATED
+		}
+	case DRAW:
+		if self.withdraws(army1, size1) {
+			event.Ctx, event.TargetArmy, event.ByArmy = RETREATED, army1.Id, army2.Id
+		} else if self.withdraws(army2, size2) {
+			event.Ctx, event.TargetArmy, event.ByArmy = RETREATED, army2.Id, army1.Id
+		}
+	}
+	return event
+}
+
+// the stance of the army sets the damage it deals, and that of its enemy the damage it takes
+func stanceSource(self ArmiesManager, e engagement, army *Army) (Modifier, bool) {
+	enemy := e.enemyOf(army)
+	value := CombatModifier(self.stanceProfile(army).Dealt-1) + CombatModifier(self.stanceProfile(enemy).Taken-1)
+	return Modifier{
+		Source: STANCE_MODIFIER,
+		Reason: fmt.Sprintf("%v against %v", army.stance(), enemy.stance()),
+		Value:  value,
+	}, true
+}
+
+func (self *ArmiesManager) stanceOrders(orders []StanceOrder) (e []StanceEvent, err error) {
+	for _, order := range orders {
+		if _, ok := self.Armies[order.ArmyId]; !ok {
+			return e, errors.New(fmt.Sprintf("order %v had invalid armyId %v", order.Id, order.ArmyId))
+		}
+		if !order.Stance.valid() || order.Stance == "" {
+			return e, errors.New(fmt.Sprintf("invalid stance %v", order.Stance))
+		}
+	}
+	for _, order := range orders {
+		army := self.Armies[order.ArmyId]
+		e = append(e, StanceEvent{
+			ArmyEvent: newArmyEvent(army.Id),
+			From:      army.stance(),
+			To:        order.Stance,
+		})
+		army.Stance = order.Stance
+	}
+	return e, nil
+}
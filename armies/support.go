@@ -0,0 +1,166 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pgruenbacher/got/regions"
+)
+
+type SupportRates struct {
+	// share of the supporting army's strength lent to the supported one
+	Share float32 `validate:"min=0,max=1"`
+}
+
+/*
+SupportOrder lends part of the strength of an army to the attack or defense of
+an army of its house or an ally, in a region adjacent to the supporter. The
+supporter holds its position, and its support is cut if it is attacked.
+*/
+type SupportOrder struct {
+	ArmyOrder
+	Supported armyId
+	Region    regions.RegionId
+}
+
+type SupportContext string
+
+const (
+	SUPPORT_ORDERED SupportContext = "SUPPORT_ORDERED"
+	SUPPORT_GIVEN   SupportContext = "SUPPORT_GIVEN"
+	SUPPORT_CUT     SupportContext = "SUPPORT_CUT"
+)
+
+type SupportEvent struct {
+	ArmyEvent
+	Supported armyId
+	Region    regions.RegionId
+	Ctx       SupportContext
+}
+
+func newSupportEvent(order SupportOrder, ctx SupportContext) SupportEvent {
+	return SupportEvent{
+		ArmyEvent: newArmyEvent(order.ArmyId),
+		Supported: order.Supported,
+		Region:    order.Region,
+		Ctx:       ctx,
+	}
+}
+
+// support orders stand until the marches of the turn are resolved
+func (self *ArmiesManager) supportOrders(orders []SupportOrder) (e []SupportEvent, err error) {
+	if err = self.validateSupportOrders(orders); err != nil {
+		return e, err
+	}
+	for _, order := range orders {
+		self.supports = append(self.supports, order)
+		e = append(e, newSupportEvent(order, SUPPORT_ORDERED))
+	}
+	return e, nil
+}
+
+func (self *ArmiesManager) withdrawSupports(orders []MarchOrder) {
+	supports := self.supports[:0]
+	for _, support := range self.supports {
+		if !marching(orders, support.ArmyId) {
+			supports = append(supports, support)
+		}
+	}
+	self.supports = supports
+}
+
+// armies attacked in any of the battles, surprised armies included
+func attackedArmies(battles battles) map[armyId]bool {
+	attacked := make(map[armyId]bool)
+	for _, battle := range battles {
+		attacked[battle.army2.Id] = true
+		if battle.ctx == SURPRISE_ATTACK {
+			attacked[battle.army1.Id] = true
+		}
+	}
+	return attacked
+}
+
+// support is cut wherever the supporter is attacked, whether or not it comes to blows
+func (self ArmiesManager) cutSupports(attacked map[armyId]bool) (events []SupportEvent) {
+	for _, support := range self.supports {
+		if attacked[support.ArmyId] {
+			events = append(events, newSupportEvent(support, SUPPORT_CUT))
+		}
+	}
+	return events
+}
+
+// supportsFor gathers the supporters of both sides of the engagement
+func (self ArmiesManager) supportsFor(e engagement, attacked map[armyId]bool) (supporters map[armyId][]*Army, events []SupportEvent) {
+	supporters = make(map[armyId][]*Army)
+	for _, support := range self.supports {
+		if attacked[support.ArmyId] || support.Region != e.field.Id {
+			continue
+		}
+		if support.Supported != e.army1.Id && support.Supported != e.army2.Id {
+			continue
+		}
+		supporter, ok := self.Armies[support.ArmyId]
+		if !ok || supporter.Size == 0 {
+			continue
+		}
+		supporters[support.Supported] = append(supporters[support.Supported], supporter)
+		events = append(events, newSupportEvent(support, SUPPORT_GIVEN))
+	}
+	return supporters, events
+}
+
+// each supporter adds a share of its strength, relative to the strength of the supported army
+func supportSource(self ArmiesManager, e engagement, army *Army) (Modifier, bool) {
+	supporters := e.supporters[army.Id]
+	if len(supporters) == 0 || army.Size == 0 {
+		return Modifier{}, false
+	}
+	var value CombatModifier
+	var ids []string
+	for _, supporter := range supporters {
+		value = value + CombatModifier(self.Config.Support.Share*float32(supporter.Size*supporter.Quality)/float32(army.Size*army.Quality))
+		ids = append(ids, string(supporter.Id))
+	}
+	return Modifier{
+		Source: SUPPORT_MODIFIER,
+		Reason: "supported by " + strings.Join(ids, ", "),
+		Value:  value,
+	}, true
+}
+
+func (self *ArmiesManager) validateSupportOrders(orders []SupportOrder) error {
+	ordered := make(map[armyId]bool)
+	for _, order := range self.supports {
+		ordered[order.ArmyId] = true
+	}
+	for _, order := range orders {
+		army, ok := self.Armies[order.ArmyId]
+		if !ok {
+			return errors.New(fmt.Sprintf("order %v had invalid armyId %v", order.Id, order.ArmyId))
+		}
+		supported, ok := self.Armies[order.Supported]
+		if !ok {
+			return errors.New(fmt.Sprintf("order %v supports invalid armyId %v", order.Id, order.Supported))
+		}
+		if army.Id == supported.Id {
+			return errors.New(fmt.Sprintf("army %v can't support itself", army.Id))
+		}
+		if ordered[army.Id] {
+			return errors.New(fmt.Sprintf("army %v already supports another army", army.Id))
+		}
+		ordered[army.Id] = true
+		if _, ok := army.Region.Edges[order.Region]; !ok {
+			return errors.New(fmt.Sprintf("army %v is not adjacent to region %v", army.Id, order.Region))
+		}
+		if !self.diplomacy.IsAlly(army.House, supported.House) {
+			return errors.New(fmt.Sprintf("army %v may only support its house or allies, not %v", army.Id, supported.House))
+		}
+		if _, ok := supported.Region.Edges[order.Region]; !ok && supported.Region.Id != order.Region {
+			return errors.New(fmt.Sprintf("army %v can't fight in region %v", supported.Id, order.Region))
+		}
+	}
+	return nil
+}
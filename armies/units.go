@@ -0,0 +1,171 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/pgruenbacher/got/regions"
+)
+
+type UnitType string
+
+const (
+	INFANTRY UnitType = "INFANTRY"
+	CAVALRY  UnitType = "CAVALRY"
+	ARCHERS  UnitType = "ARCHERS"
+	SIEGE    UnitType = "SIEGE"
+)
+
+// Composition is the number of men of each unit type, summing to the army size.
+type Composition map[UnitType]int
+
+type UnitProfile struct {
+	// combat modifier of the unit wherever it fights
+	Strength CombatModifier
+	// added to the movement penalty of the army, the slowest unit sets the pace
+	MovementPenalty int
+	// combat modifier by the terrain of the battle
+	Terrain map[regions.Terrain]CombatModifier
+	// combat modifier when defending behind a boundary
+	Boundary map[regions.BoundaryKind]CombatModifier
+	// combat modifier when assaulting a castle
+	Assault CombatModifier
+}
+
+func (self Composition) total() (total int) {
+	for _, count := range self {
+		total = total + count
+	}
+	return total
+}
+
+func (self Composition) types() (types []string) {
+	for unitType := range self {
+		types = append(types, string(unitType))
+	}
+	sort.Strings(types)
+	return types
+}
+
+// scaled spreads the size over the unit types in the same proportions. The
+// composition is replaced rather than changed in place.
+func (self Composition) scaled(size int) Composition {
+	total := self.total()
+	if total == 0 {
+		return self
+	}
+	scaled := make(Composition, len(self))
+	left := size
+	types := self.types()
+	for _, unitType := range types {
+		count := self[UnitType(unitType)] * size / total
+		scaled[UnitType(unitType)] = count
+		left = left - count
+	}
+	// hand out what rounding left over
+	for i := 0; left > 0; i++ {
+		scaled[UnitType(types[i%len(types)])]++
+		left--
+	}
+	return scaled
+}
+
+func (self Composition) copy() Composition {
+	if self == nil {
+		return nil
+	}
+	return Composition{}.add(self)
+}
+
+func (self Composition) add(other Composition) Composition {
+	sum := make(Composition, len(self)+len(other))
+	for unitType, count := range self {
+		sum[unitType] = sum[unitType] + count
+	}
+	for unitType, count := range other {
+		sum[unitType] = sum[unitType] + count
+	}
+	return sum
+}
+
+// composed is the composition of the army, which is all infantry if it has none
+func (self Army) composed() Composition {
+	if len(self.Composition) == 0 {
+		return Composition{INFANTRY: self.Size}
+	}
+	return self.Composition
+}
+
+// the unit types are only checked once the units of the config are known
+func (self Army) validateComposition(units map[UnitType]UnitProfile) error {
+	for unitType, count := range self.Composition {
+		if count < 0 {
+			return errors.New(fmt.Sprintf("army %v has %v %v", self.Id, count, unitType))
+		}
+		if _, ok := units[unitType]; units != nil && !ok {
+			return errors.New(fmt.Sprintf("army %v has unknown unit type %v", self.Id, unitType))
+		}
+	}
+	if len(self.Composition) > 0 && self.Composition.total() != self.Size {
+		return errors.New(fmt.Sprintf("army %v composition doesn't add up to its size %v", self.Id, self.Size))
+	}
+	return nil
+}
+
+// armies without a composition are left as they are
+func (self *Army) syncComposition() {
+	if len(self.Composition) > 0 && self.Composition.total() != self.Size {
+		self.Composition = self.Composition.scaled(self.Size)
+	}
+}
+
+// weighted over the men of the army
+func (self ArmiesManager) compositionModifier(army *Army, modifier func(UnitProfile) CombatModifier) CombatModifier {
+	total := army.Composition.total()
+	if total == 0 {
+		return 0
+	}
+	var sum CombatModifier
+	for unitType, count := range army.Composition {
+		if profile, ok := self.Config.Units[unitType]; ok {
+			sum = sum + modifier(profile)*CombatModifier(count)
+		}
+	}
+	return sum / CombatModifier(total)
+}
+
+// compositionBonus of an army fighting on the field, the defender benefits from the boundary
+func (self ArmiesManager) compositionBonus(army *Army, field *regions.Region, boundary regions.Boundary, defending bool) CombatModifier {
+	return self.compositionModifier(army, func(profile UnitProfile) CombatModifier {
+		bonus := profile.Strength + profile.Terrain[field.Terrain]
+		if defending {
+			bonus = bonus + profile.Boundary[boundary.Kind()]
+		}
+		return bonus
+	})
+}
+
+func (self ArmiesManager) assaultBonus(army *Army) CombatModifier {
+	return self.compositionModifier(army, func(profile UnitProfile) CombatModifier {
+		return profile.Strength + profile.Assault
+	})
+}
+
+// an army marches at the pace of its slowest unit
+func (self ArmiesManager) compositionPenalty(army *Army) (penalty int) {
+	for unitType, count := range army.Composition {
+		if profile, ok := self.Config.Units[unitType]; ok && count > 0 && profile.MovementPenalty > penalty {
+			penalty = profile.MovementPenalty
+		}
+	}
+	return penalty
+}
+
+// battles are fought in the region of the army being attacked, across the boundary between them
+func battleground(b battle) (*regions.Region, regions.Boundary) {
+	if edge, ok := b.army1.Region.Edges[b.army2.Region.Id]; ok {
+		return b.army2.Region, edge.Boundary
+	}
+	return b.army2.Region, new(regions.NoBoundary)
+}
@@ -0,0 +1,65 @@
+package armies
+
+import (
+	"math"
+
+	"github.com/pgruenbacher/got/economy"
+	"github.com/pgruenbacher/got/families"
+)
+
+type UpkeepRates struct {
+	// gold per unit of size and quality
+	Gold float32
+	// food per unit of size
+	Food float32
+}
+
+// morale lost to unpaid wages and men deserting from hunger
+type DeficitEvent struct {
+	ArmyEvent
+	MoraleLost int
+	Deserted   int
+}
+
+func (self ArmiesManager) armyUpkeep(army *Army) economy.Resources {
+	return economy.Resources{
+		Gold: int(math.Ceil(float64(self.Config.Upkeep.Gold * float32(army.Size*army.Quality)))),
+		Food: int(math.Ceil(float64(self.Config.Upkeep.Food * float32(army.Size)))),
+	}
+}
+
+// Upkeep is the cost of all the armies of the house for one turn.
+func (self ArmiesManager) Upkeep(houseId families.HouseId) (upkeep economy.Resources) {
+	for _, army := range self.Armies.OfHouse(houseId) {
+		upkeep = upkeep.Add(self.armyUpkeep(army))
+	}
+	return upkeep
+}
+
+// ApplyDeficit punishes the armies of a house that can't pay for them. Unpaid
+// armies lose morale, and hungry ones lose men to desertion.
+func (self *ArmiesManager) ApplyDeficit(houseId families.HouseId, deficit economy.Resources) (events []DeficitEvent) {
+	if deficit.Gold <= 0 && deficit.Food <= 0 {
+		return events
+	}
+	for _, army := range self.Armies.OfHouse(houseId) {
+		e := DeficitEvent{ArmyEvent: newArmyEvent(army.Id)}
+		if deficit.Gold > 0 && army.Morale > 1 {
+			army.Morale--
+			e.MoraleLost = 1
+		}
+		if deficit.Food > 0 {
+			e.Deserted = army.Size / 10
+			if e.Deserted < 1 {
+				e.Deserted = 1
+			}
+			if e.Deserted >= army.Size {
+				e.Deserted = army.Size - 1
+			}
+			army.Size = army.Size - e.Deserted
+			army.syncComposition()
+		}
+		events = append(events, e)
+	}
+	return events
+}
@@ -0,0 +1,145 @@
+package armies
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pgruenbacher/got/regions"
+)
+
+type Severity string
+
+const (
+	// the order can't be carried out
+	ERROR Severity = "ERROR"
+	// the order can be carried out, but likely not as the house intends
+	WARNING Severity = "WARNING"
+)
+
+type ValidationCode string
+
+const (
+	UNKNOWN_ARMY   ValidationCode = "UNKNOWN_ARMY"
+	UNKNOWN_REGION ValidationCode = "UNKNOWN_REGION"
+	WRONG_SOURCE   ValidationCode = "WRONG_SOURCE"
+	MISSING_EDGE   ValidationCode = "MISSING_EDGE"
+	// a neutral army stands in the destination, the march is cancelled unless it leaves
+	NEUTRAL_PRESENT ValidationCode = "NEUTRAL_PRESENT"
+	// the destination is held by a neutral house, the march is cancelled
+	NEUTRAL_TERRITORY ValidationCode = "NEUTRAL_TERRITORY"
+	LEAVES_SUPPLY     ValidationCode = "LEAVES_SUPPLY"
+	RIVER_CROSSING    ValidationCode = "RIVER_CROSSING"
+	DUPLICATE_ORDER   ValidationCode = "DUPLICATE_ORDER"
+)
+
+type ValidationRates struct {
+	// river crossings at least this slow are warned of, any slowing crossing if unset
+	RiverPenalty int
+}
+
+type Finding struct {
+	Severity Severity
+	Code     ValidationCode
+	Message  string
+}
+
+func (self Finding) String() string {
+	return fmt.Sprintf("%v %v: %v", self.Severity, self.Code, self.Message)
+}
+
+// OrderValidation holds all that was found wrong with an order.
+type OrderValidation struct {
+	Order    MarchOrder
+	Findings []Finding
+}
+
+func (self *OrderValidation) refuse(code ValidationCode, format string, a ...interface{}) {
+	self.Findings = append(self.Findings, Finding{ERROR, code, fmt.Sprintf(format, a...)})
+}
+
+func (self *OrderValidation) warn(code ValidationCode, format string, a ...interface{}) {
+	self.Findings = append(self.Findings, Finding{WARNING, code, fmt.Sprintf(format, a...)})
+}
+
+// Err is the first error found, nil if the order can be carried out.
+func (self OrderValidation) Err() error {
+	for _, f := range self.Findings {
+		if f.Severity == ERROR {
+			return errors.New(f.Message)
+		}
+	}
+	return nil
+}
+
+func (self OrderValidation) Warnings() (warnings []Finding) {
+	for _, f := range self.Findings {
+		if f.Severity == WARNING {
+			warnings = append(warnings, f)
+		}
+	}
+	return warnings
+}
+
+// ValidateMarchOrders checks every order, rather than stopping at the first bad one.
+func (self ArmiesManager) ValidateMarchOrders(orders []MarchOrder) (results []OrderValidation) {
+	ordered := make(map[armyId]bool)
+	for _, order := range orders {
+		v := OrderValidation{Order: order}
+		self.validateMarch(&v)
+		if ordered[order.ArmyId] {
+			v.warn(DUPLICATE_ORDER, "army %v was already given a march order, only the first will be carried out", order.ArmyId)
+		}
+		ordered[order.ArmyId] = true
+		results = append(results, v)
+	}
+	return results
+}
+
+func (self ArmiesManager) validateMarch(v *OrderValidation) {
+	order := v.Order
+	army, ok := self.Armies[order.ArmyId]
+	if !ok {
+		v.refuse(UNKNOWN_ARMY, "order %v had invalid armyId %v", order.Id, order.ArmyId)
+		return
+	}
+	if _, ok := self.regions[order.Src]; !ok {
+		v.refuse(UNKNOWN_REGION, "invalid src id %v", order.Src)
+		return
+	}
+	if army.Region.Id != order.Src {
+		v.refuse(WRONG_SOURCE, "army region %v doesn't match src %v", army.Region.Id, order.Src)
+		return
+	}
+	dst, ok := self.regions[order.Dst]
+	if !ok {
+		v.refuse(UNKNOWN_REGION, "invalid destination id %v", order.Dst)
+		return
+	}
+	edge, ok := army.Region.Edges[order.Dst]
+	if !ok {
+		v.refuse(MISSING_EDGE, "none of the army region  %v edges match army destination %v", army.Region.Id, order.Dst)
+		return
+	}
+	for _, other := range armiesWithin(self.Armies, dst) {
+		if !self.diplomacy.IsAlly(army.House, other.House) && !self.diplomacy.IsEnemy(army.House, other.House) {
+			v.warn(NEUTRAL_PRESENT, "neutral army %v of house %v stands in %v, the march is cancelled unless it leaves", other.Id, other.House, dst.Id)
+			break
+		}
+	}
+	if self.neutralTerritory(army, dst) {
+		v.warn(NEUTRAL_TERRITORY, "%v is held by neutral house %v, which hasn't granted passage", dst.Id, dst.Controller)
+	}
+	if edge.Boundary.Kind() == regions.RIVER {
+		penalty := edge.Boundary.MovePenalty()
+		if penalty > 0 && penalty >= self.Config.Validation.RiverPenalty {
+			v.warn(RIVER_CROSSING, "crossing the river into %v slows the march by %v", dst.Id, penalty)
+		}
+	}
+	if army.EvalSupplyRoute(self.regions, friendlyFilter(army, self.diplomacy)) {
+		moved := newArmy(army)
+		moved.Region = dst
+		if !moved.EvalSupplyRoute(self.regions, friendlyFilter(moved, self.diplomacy)) {
+			v.warn(LEAVES_SUPPLY, "army %v would be cut off from its home %v in %v", army.Id, army.Home.Id, dst.Id)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package armies
+
+type VeterancyRates struct {
+	// experience gained for surviving a battle, and extra for winning it
+	Battle  int
+	Victory int
+	// experience needed to raise quality by one
+	PerQuality int
+	// quality of the fresh recruits reinforcing an army
+	RecruitQuality int
+}
+
+type ExperienceEvent struct {
+	ArmyEvent
+	Gained     int
+	Experience int
+	Quality    int
+	// quality was raised by experience, or lowered by fresh recruits
+	Promoted bool
+	Diluted  bool
+}
+
+// gainExperience rewards the armies that survived a battle, and the victor most.
+func (self ArmiesManager) gainExperience(event CombatEvent, target, by *Army) (events []ExperienceEvent) {
+	rates := self.Config.Veterancy
+	victory := event.Ctx != DRAW
+	for _, army := range []*Army{target, by} {
+		if army == nil || army.Size <= 0 {
+			continue
+		}
+		gained := rates.Battle
+		if victory && army == by {
+			gained = gained + rates.Victory
+		}
+		if gained <= 0 {
+			continue
+		}
+		events = append(events, self.addExperience(army, gained))
+	}
+	return events
+}
+
+// experience is turned into quality as long as quality can still rise
+func (self ArmiesManager) addExperience(army *Army, gained int) ExperienceEvent {
+	army.Experience = army.Experience + gained
+	perQuality := self.Config.Veterancy.PerQuality
+	e := ExperienceEvent{
+		ArmyEvent: newArmyEvent(army.Id),
+		Gained:    gained,
+	}
+	for perQuality > 0 && army.Experience >= perQuality && army.Quality < 5 {
+		army.Experience = army.Experience - perQuality
+		army.Quality++
+		e.Promoted = true
+	}
+	e.Experience = army.Experience
+	e.Quality = army.Quality
+	return e
+}
+
+// dilute mixes fresh recruits into a veteran army, its experience is spread
+// over the new men and its quality drops toward theirs.
+func (self ArmiesManager) dilute(army *Army, recruits int) (e ExperienceEvent) {
+	e.ArmyEvent = newArmyEvent(army.Id)
+	size := army.Size - recruits
+	if recruits <= 0 || size <= 0 {
+		e.Experience, e.Quality = army.Experience, army.Quality
+		return e
+	}
+	army.Experience = army.Experience * size / army.Size
+	recruitQuality := self.Config.Veterancy.RecruitQuality
+	if recruitQuality < 1 {
+		recruitQuality = 1
+	}
+	if quality := roundedDiv(army.Quality*size+recruitQuality*recruits, army.Size); quality < army.Quality {
+		army.Quality = quality
+		e.Diluted = true
+	}
+	e.Experience, e.Quality = army.Experience, army.Quality
+	return e
+}
@@ -0,0 +1,188 @@
+package characters
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/validator.v2"
+
+	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
+)
+
+var (
+	CharacterUnavailable = errors.New("character is dead or captured")
+)
+
+type CharacterId string
+
+type Characters map[CharacterId]*Character
+
+type Status string
+
+const (
+	ALIVE    Status = "ALIVE"
+	CAPTURED Status = "CAPTURED"
+	DEAD     Status = "DEAD"
+)
+
+type Trait string
+
+const (
+	// commanders inspiring their men rally morale faster
+	INSPIRING Trait = "INSPIRING"
+	// brave commanders fight harder but fall more often
+	BRAVE Trait = "BRAVE"
+	// cautious commanders are rarely killed or captured
+	CAUTIOUS   Trait = "CAUTIOUS"
+	HONORABLE  Trait = "HONORABLE"
+	DIPLOMATIC Trait = "DIPLOMATIC"
+)
+
+/*
+Characters are the members of a house. They command armies and carry proposals
+between houses as envoys.
+*/
+type Character struct {
+	Id    CharacterId
+	Name  string
+	House families.HouseId `validate:"nonzero"`
+	Age   int              `validate:"min=0"`
+	// Skill is the ability of the character as a commander, on the same scale as army quality.
+	Skill  int `validate:"min=1,max=5"`
+	Traits []Trait
+	Status Status
+	// house holding the character prisoner
+	CapturedBy families.HouseId
+	// Head marks the character leading the house.
+	Head bool `toml:"head"`
+	// Heir is designated by the head, and is first in the line of succession.
+	Heir CharacterId `toml:"heir"`
+}
+
+// Events
+type CharacterEvent struct {
+	events.Event
+	CharacterId CharacterId
+	Status      Status
+	By          families.HouseId
+}
+
+func (self Characters) InitializeAll(h families.Houses) error {
+	for characterId, character := range self {
+		if err := validator.Validate(character); err != nil {
+			return err
+		}
+		character.Id = characterId
+		if _, ok := h[character.House]; !ok {
+			return errors.New(fmt.Sprintf("character %v house %v does not exist", characterId, character.House))
+		}
+		if character.Status == "" {
+			character.Status = ALIVE
+		}
+	}
+	return nil
+}
+
+// Clone copies the characters, so that their fates may change without touching the original.
+func (self Characters) Clone() Characters {
+	clone := make(Characters, len(self))
+	for id, character := range self {
+		c := *character
+		c.Traits = append([]Trait(nil), character.Traits...)
+		clone[id] = &c
+	}
+	return clone
+}
+
+// Members returns the living members of the house that are free to act.
+func (self Characters) Members(houseId families.HouseId) (members []*Character) {
+	for _, character := range self {
+		if character.House == houseId && character.Available() {
+			members = append(members, character)
+		}
+	}
+	return members
+}
+
+func (self Character) Available() bool {
+	return self.Status == ALIVE
+}
+
+func (self Character) HasTrait(trait Trait) bool {
+	for _, t := range self.Traits {
+		if t == trait {
+			return true
+		}
+	}
+	return false
+}
+
+// Rally is the morale an army under this character's command regains per turn of rest.
+func (self Character) Rally() int {
+	rally := self.Skill / 2
+	if self.HasTrait(INSPIRING) {
+		rally++
+	}
+	return rally
+}
+
+func (self *Character) Kill(by families.HouseId) CharacterEvent {
+	self.Status = DEAD
+	self.CapturedBy = ""
+	return newCharacterEvent(self.Id, DEAD, by)
+}
+
+func (self *Character) Capture(by families.HouseId) CharacterEvent {
+	self.Status = CAPTURED
+	self.CapturedBy = by
+	return newCharacterEvent(self.Id, CAPTURED, by)
+}
+
+func (self *Character) Release() CharacterEvent {
+	by := self.CapturedBy
+	self.Status = ALIVE
+	self.CapturedBy = ""
+	return newCharacterEvent(self.Id, ALIVE, by)
+}
+
+func newCharacterEvent(id CharacterId, status Status, by families.HouseId) CharacterEvent {
+	return CharacterEvent{
+		Event:       events.NewEvent(),
+		CharacterId: id,
+		Status:      status,
+		By:          by,
+	}
+}
+
+var ExampleCharacters = `
+    [eddard]
+    name="eddard stark"
+    house="house1"
+    age=35
+    skill=4
+    traits=["HONORABLE","INSPIRING"]
+    head=true
+    heir="robb"
+
+    [robb]
+    name="robb stark"
+    house="house1"
+    age=15
+    skill=3
+
+    [jaime]
+    name="jaime lannister"
+    house="house2"
+    age=31
+    skill=5
+    traits=["BRAVE"]
+    head=true
+
+    [kevan]
+    name="kevan lannister"
+    house="house2"
+    age=52
+    skill=2
+    traits=["CAUTIOUS","DIPLOMATIC"]
+`
@@ -0,0 +1,108 @@
+package characters
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
+)
+
+var (
+	NoSuccessor = errors.New("house has no one left in the line of succession")
+)
+
+// the leadership of a house passes on after the death of its head
+type SuccessionEvent struct {
+	events.Event
+	HouseId families.HouseId
+	From    CharacterId
+	To      CharacterId
+}
+
+type byAge []*Character
+
+func (a byAge) Len() int      { return len(a) }
+func (a byAge) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byAge) Less(i, j int) bool {
+	if a[i].Age == a[j].Age {
+		return a[i].Id < a[j].Id
+	}
+	return a[i].Age > a[j].Age
+}
+
+// Head returns the leader of the house. A captured head still leads, a dead one does not.
+func (self Characters) Head(houseId families.HouseId) *Character {
+	for _, character := range self {
+		if character.House == houseId && character.Head && character.Status != DEAD {
+			return character
+		}
+	}
+	return nil
+}
+
+// SuccessionLine orders the living, free members of the house. The heir
+// designated by the last head comes first, then the rest eldest first.
+func (self Characters) SuccessionLine(houseId families.HouseId) (line []*Character) {
+	var heir CharacterId
+	for _, character := range self {
+		if character.House == houseId && character.Head {
+			heir = character.Heir
+		}
+	}
+	var rest byAge
+	for _, character := range self.Members(houseId) {
+		if character.Head {
+			continue
+		}
+		if character.Id == heir {
+			line = append(line, character)
+		} else {
+			rest = append(rest, character)
+		}
+	}
+	sort.Sort(rest)
+	return append(line, rest...)
+}
+
+// Captives returns the living members of the house held prisoner, they stay in
+// the line of succession and may take the lead once released.
+func (self Characters) Captives(houseId families.HouseId) (captives []*Character) {
+	for _, character := range self {
+		if character.House == houseId && character.Status == CAPTURED {
+			captives = append(captives, character)
+		}
+	}
+	return captives
+}
+
+// NeedsSuccession is true when the house had members but its head is gone.
+func (self Characters) NeedsSuccession(houseId families.HouseId) bool {
+	for _, character := range self {
+		if character.House == houseId {
+			return self.Head(houseId) == nil
+		}
+	}
+	return false
+}
+
+// Succeed passes the leadership to the first in the line of succession.
+func (self Characters) Succeed(houseId families.HouseId) (e SuccessionEvent, err error) {
+	line := self.SuccessionLine(houseId)
+	if len(line) == 0 {
+		return e, NoSuccessor
+	}
+	e = SuccessionEvent{
+		Event:   events.NewEvent(),
+		HouseId: houseId,
+		To:      line[0].Id,
+	}
+	for _, character := range self {
+		if character.House == houseId && character.Head {
+			e.From = character.Id
+			character.Head = false
+		}
+	}
+	line[0].Head = true
+	return e, nil
+}
@@ -0,0 +1,94 @@
+/*
+Command preview shows what the orders of a house are expected to bring about
+this turn, without resolving them.
+
+	preview -house house1 -orders orders.toml -config modifiers.toml -save game.json -seed 7
+
+Without an orders file or config the examples of the armies package are used,
+and without a save the orders are given in the example realm. The same seed
+gives the same battles.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pgruenbacher/got/armies"
+	"github.com/pgruenbacher/got/families"
+	"github.com/pgruenbacher/got/game"
+)
+
+func main() {
+	house := flag.String("house", "house1", "house giving the orders")
+	ordersFile := flag.String("orders", "", "orders of the house for the turn")
+	configFile := flag.String("config", "", "combat modifiers and resolver config")
+	saveFile := flag.String("save", "", "saved game to give the orders in")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed of the battles")
+	flag.Parse()
+
+	g, err := load(*saveFile)
+	if err != nil {
+		fail(err)
+	}
+	if *configFile != "" {
+		g.Armies.Config = armies.Config{}
+		if err := decode(*configFile, "", &g.Armies.Config); err != nil {
+			fail(err)
+		}
+	}
+	var orders armies.Orders
+	if err := decode(*ordersFile, armies.ExampleOrders, &orders); err != nil {
+		fail(err)
+	}
+	preview := g.Armies.Preview(families.HouseId(*house), orders, *seed)
+	fmt.Printf("%v events\n", len(preview.Events))
+	for _, e := range preview.Events {
+		fmt.Printf("  %+v\n", e)
+	}
+	fmt.Printf("%v battles\n", len(preview.Battles))
+	for _, b := range preview.Battles {
+		fmt.Printf("  %v against %v: %v\n", b.ByArmy, b.TargetArmy, b.Ctx)
+		for _, m := range b.ByModifiers {
+			fmt.Printf("    %-12v %+.2f %v\n", m.Source, m.Value, m.Reason)
+		}
+	}
+	fmt.Printf("%v warnings\n", len(preview.Warnings))
+	for _, w := range preview.Warnings {
+		fmt.Printf("  %v\n", w)
+	}
+}
+
+func load(file string) (*game.Game, error) {
+	if file == "" {
+		return game.Example()
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return game.Load(f)
+}
+
+func decode(file, example string, v interface{}) error {
+	if file == "" {
+		_, err := toml.Decode(example, v)
+		return err
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	_, err = toml.Decode(string(data), v)
+	return err
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,83 @@
+/*
+Command simulate gives the odds of a battle described in a scenario file.
+
+	simulate -scenario battle.toml -config modifiers.toml -runs 5000
+
+Without a scenario or config the examples of the armies package are used.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pgruenbacher/got/armies"
+)
+
+func main() {
+	scenarioFile := flag.String("scenario", "", "scenario file describing the battle")
+	configFile := flag.String("config", "", "combat modifiers and resolver config")
+	runs := flag.Int("runs", 0, "number of battles to fight, overrides the scenario")
+	seed := flag.Int64("seed", 0, "seed of the random source, overrides the scenario")
+	resolver := flag.String("resolver", "", "combat resolver, overrides the config")
+	flag.Parse()
+
+	var manager armies.ArmiesManager
+	if err := decode(*configFile, armies.ExampleModifiers, &manager.Config); err != nil {
+		fail(err)
+	}
+	var scenario armies.Scenario
+	if err := decode(*scenarioFile, armies.ExampleScenario, &scenario); err != nil {
+		fail(err)
+	}
+	if *runs > 0 {
+		scenario.Runs = *runs
+	}
+	if *seed != 0 {
+		scenario.Seed = *seed
+	}
+	if *resolver != "" {
+		manager.Config.Resolver.Name = *resolver
+	}
+	odds, err := manager.Simulate(scenario)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("%v battles\n", odds.Runs)
+	printOutcomes("attacker", odds.Attacker, odds.AttackerLosses)
+	printOutcomes("defender", odds.Defender, odds.DefenderLosses)
+}
+
+func decode(file, example string, v interface{}) error {
+	if file == "" {
+		_, err := toml.Decode(example, v)
+		return err
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	_, err = toml.Decode(string(data), v)
+	return err
+}
+
+func printOutcomes(side string, outcomes map[armies.CombatContext]float32, losses float32) {
+	fmt.Printf("%v: expected losses %.1f\n", side, losses)
+	var ctxs []string
+	for ctx := range outcomes {
+		ctxs = append(ctxs, string(ctx))
+	}
+	sort.Strings(ctxs)
+	for _, ctx := range ctxs {
+		fmt.Printf("  %-12v %5.1f%%\n", ctx, outcomes[armies.CombatContext(ctx)]*100)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
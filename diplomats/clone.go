@@ -0,0 +1,57 @@
+package diplomats
+
+import (
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/families"
+)
+
+/*
+Clone copies the table onto the copied houses and characters. Each relation
+stays shared by its two houses in the copy. The starting relations are only
+read when the table is initialized, and are shared with the original.
+*/
+func (self *DiplomatsTable) Clone(h families.Houses, c characters.Characters) DiplomatsTable {
+	clone := DiplomatsTable{
+		Starting_relations: self.Starting_relations,
+		houses:             h,
+	}
+	if self.RelationsTable != nil {
+		cloned := make(map[*Relation]*Relation)
+		clone.RelationsTable = make(map[families.HouseId]Relations, len(self.RelationsTable))
+		for houseId, relations := range self.RelationsTable {
+			r := make(Relations, len(relations))
+			for other, relation := range relations {
+				if _, ok := cloned[relation]; !ok {
+					copied := *relation
+					copied.house1, copied.house2 = rebindHouse(relation.house1, h), rebindHouse(relation.house2, h)
+					cloned[relation] = &copied
+				}
+				r[other] = cloned[relation]
+			}
+			clone.RelationsTable[houseId] = r
+		}
+	}
+	if self.Factions != nil {
+		clone.Factions = make(Factions, len(self.Factions))
+		for factionId, faction := range self.Factions {
+			f := *faction
+			f.Members = append([]families.HouseId(nil), faction.Members...)
+			f.Wars = append([]FactionId(nil), faction.Wars...)
+			clone.Factions[factionId] = &f
+		}
+	}
+	for _, p := range self.proposals {
+		if p.envoy != nil {
+			p.envoy = c[p.envoy.Id]
+		}
+		clone.proposals = append(clone.proposals, p)
+	}
+	return clone
+}
+
+func rebindHouse(house *families.House, h families.Houses) *families.House {
+	if house == nil {
+		return nil
+	}
+	return h[house.Id]
+}
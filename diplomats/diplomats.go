@@ -1,10 +1,19 @@
 package diplomats
 
-import "github.com/pgruenbacher/got/families"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
+)
 
 type Proposal struct {
 	from families.HouseId
 	to   families.HouseId
+	// the character carrying the proposal between the houses
+	envoy *characters.Character
 }
 
 type PeaceProposal struct {
@@ -17,6 +26,9 @@ type DiplomatsTable struct {
 	// only one relation may exist between each house.
 	RelationsTable map[families.HouseId]Relations
 
+	// coalitions of houses, membership overrides the pairwise relations
+	Factions Factions `toml:"factions"`
+
 	houses families.Houses
 	// pending proposals that are collected by orders and then forwarded to players on next turn
 	proposals []Proposal
@@ -36,12 +48,35 @@ const (
 	UNKNOWN  RelationStatus = "UNKNOWN"
 )
 
+// houses are enemies if they are, or their rulers are, at war
 func (self *DiplomatsTable) IsEnemy(houseId1, houseId2 families.HouseId) bool {
-	return self.RelationsTable[houseId1][houseId2].OfficialStatus == ENEMY
+	ruler1, ruler2 := self.ruler(houseId1), self.ruler(houseId2)
+	if ruler1 == ruler2 {
+		return false
+	}
+	return self.hasStatus(houseId1, houseId2, ENEMY) || self.hasStatus(ruler1, ruler2, ENEMY) ||
+		self.factionsAtWar(houseId1, houseId2) || self.factionsAtWar(ruler1, ruler2)
 }
 
+// a house is always allied with itself and the rest of its realm, so their armies may stack
 func (self *DiplomatsTable) IsAlly(houseId1, houseId2 families.HouseId) bool {
-	return self.RelationsTable[houseId1][houseId2].OfficialStatus == ALLIED
+	ruler1, ruler2 := self.ruler(houseId1), self.ruler(houseId2)
+	if ruler1 == ruler2 {
+		return true
+	}
+	return self.hasStatus(houseId1, houseId2, ALLIED) || self.hasStatus(ruler1, ruler2, ALLIED) ||
+		self.sameFaction(houseId1, houseId2) || self.sameFaction(ruler1, ruler2)
+}
+
+func (self *DiplomatsTable) hasStatus(houseId1, houseId2 families.HouseId, status OfficialStatus) bool {
+	if relation := self.relation(houseId1, houseId2); relation != nil {
+		return relation.OfficialStatus == status
+	}
+	return false
+}
+
+func (self *DiplomatsTable) relation(houseId1, houseId2 families.HouseId) *Relation {
+	return self.RelationsTable[houseId1][houseId2]
 }
 
 type Relation struct {
@@ -53,8 +88,10 @@ type Relation struct {
 
 func (self *DiplomatsTable) Init(h families.Houses) error {
 	self.houses = h
-	err := self.initalizeRelations()
-	return err
+	if err := self.initalizeRelations(); err != nil {
+		return err
+	}
+	return self.initializeFactions()
 }
 
 // create the relations table from the starting relations.
@@ -112,10 +149,80 @@ func (self *DiplomatsTable) alreadyExists(h1, h2 families.HouseId) bool {
 	return false
 }
 
+// Propose queues the proposal to be forwarded on the next turn. The envoy must be
+// a free member of the proposing house.
+func (self *DiplomatsTable) Propose(p Proposal) error {
+	if _, ok := self.houses[p.to]; !ok {
+		return errors.New(fmt.Sprintf("proposal to nonexistent house %v", p.to))
+	}
+	if p.envoy == nil {
+		return errors.New(fmt.Sprintf("proposal from %v has no envoy", p.from))
+	}
+	if p.envoy.House != p.from {
+		return errors.New(fmt.Sprintf("envoy %v is not a member of house %v", p.envoy.Id, p.from))
+	}
+	if !p.envoy.Available() {
+		return characters.CharacterUnavailable
+	}
+	self.proposals = append(self.proposals, p)
+	return nil
+}
+
+// an eliminated house was removed from the table along with its relations and pending proposals
+type RemovalEvent struct {
+	events.Event
+	HouseId   families.HouseId
+	Relations int
+	Proposals int
+	// the vassals of the removed house and their new allegiance
+	Vassals []families.AllegianceEvent
+}
+
+// RemoveHouse clears an eliminated house out of the table. Its vassals pass to the successor.
+func (self *DiplomatsTable) RemoveHouse(houseId, successor families.HouseId) RemovalEvent {
+	e := RemovalEvent{
+		Event:   events.NewEvent(),
+		HouseId: houseId,
+	}
+	if self.FactionOf(houseId) != nil {
+		self.LeaveFaction(houseId)
+	}
+	proposals := self.proposals[:0]
+	for _, p := range self.proposals {
+		if p.from == houseId || p.to == houseId {
+			e.Proposals++
+			continue
+		}
+		proposals = append(proposals, p)
+	}
+	self.proposals = proposals
+	e.Relations = len(self.RelationsTable[houseId])
+	delete(self.RelationsTable, houseId)
+	for _, relations := range self.RelationsTable {
+		delete(relations, houseId)
+	}
+	e.Vassals = self.houses.Remove(houseId, successor)
+	return e
+}
+
 /*
  * Constructors
  *
  */
+func NewProposal(from, to families.HouseId, envoy *characters.Character) Proposal {
+	return Proposal{
+		from:  from,
+		to:    to,
+		envoy: envoy,
+	}
+}
+
+func NewPeaceProposal(from, to families.HouseId, envoy *characters.Character) PeaceProposal {
+	return PeaceProposal{
+		Proposal: NewProposal(from, to, envoy),
+	}
+}
+
 func newRelation(h1, h2 *families.House) *Relation {
 	return &Relation{
 		house1:         h1,
@@ -130,4 +237,22 @@ var ExampleTable = `
     [relations.house1.house2]
     official_status="ENEMY"
     relation_status="HATRED"  
+
+    [factions.reach]
+    name="the reach"
+    leader="house4"
+    members=["house4","house3"]
+    `
+
+// relations for the houses of families.ExampleVassals, whose factions leave out the vassal
+var ExampleVassalTable = `
+    
+    [relations.house1.house2]
+    official_status="ENEMY"
+    relation_status="HATRED"  
+
+    [factions.reach]
+    name="the reach"
+    leader="house4"
+    members=["house4","house5"]
     `
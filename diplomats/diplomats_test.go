@@ -27,3 +27,76 @@ func TestTable(t *testing.T) {
 		t.Error("inccorect equal relations")
 	}
 }
+
+func TestFactions(t *testing.T) {
+	var table DiplomatsTable
+	if _, err := toml.Decode(ExampleTable, &table); err != nil {
+		t.Error(err)
+	}
+	var h families.Houses
+	if _, err := toml.Decode(families.ExampleHouses, &h); err != nil {
+		t.Error(err)
+	}
+	h.InitializeAll()
+	if err := table.Init(h); err != nil {
+		t.Error(err)
+		return
+	}
+	if !table.IsAlly("house3", "house4") || table.RelationsTable["house3"]["house4"].OfficialStatus != ALLIED {
+		t.Error("faction members should be allied")
+	}
+	table.Factions["north"] = &Faction{Id: "north"}
+	if err := table.JoinFaction("north", "house1"); err != nil {
+		t.Error(err)
+	}
+	if err := table.DeclareWar("house3", "north"); err != NotFactionLeader {
+		t.Error("expected only the leader to declare war", err)
+	}
+	if err := table.DeclareWar("house4", "north"); err != nil {
+		t.Error(err)
+	}
+	if !table.IsEnemy("house1", "house3") || !table.IsEnemy("house4", "house1") {
+		t.Error("war should be shared by all faction members")
+	}
+	if err := table.LeaveFaction("house4"); err != nil {
+		t.Error(err)
+	}
+	if table.Factions["reach"].Leader != "house3" {
+		t.Error("leadership should pass to the remaining member")
+	}
+	if table.IsEnemy("house4", "house1") || table.IsAlly("house4", "house3") {
+		t.Error("house leaving faction should revert to neutral")
+	}
+}
+
+func TestVassals(t *testing.T) {
+	var table DiplomatsTable
+	if _, err := toml.Decode(ExampleVassalTable, &table); err != nil {
+		t.Error(err)
+	}
+	var h families.Houses
+	if _, err := toml.Decode(families.ExampleVassals, &h); err != nil {
+		t.Error(err)
+	}
+	if err := h.InitializeAll(); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := table.Init(h); err != nil {
+		t.Error(err)
+		return
+	}
+	if !table.IsAlly("house3", "house1") || !table.IsEnemy("house3", "house2") {
+		t.Error("vassal should inherit the stance of its liege")
+	}
+	if _, err := table.SwearFealty("house1", "house3"); err != families.CyclicFealty {
+		t.Error("expected cyclic fealty to be refused", err)
+	}
+	e, err := table.Rebel("house3")
+	if err != nil {
+		t.Error(err)
+	}
+	if e.Liege != "house1" || !table.IsEnemy("house3", "house1") || table.IsEnemy("house3", "house2") {
+		t.Error("rebel should be at war with its former liege only")
+	}
+}
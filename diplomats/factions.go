@@ -0,0 +1,234 @@
+package diplomats
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pgruenbacher/got/families"
+)
+
+var (
+	FactionNonexist  = errors.New("faction does not exist")
+	AlreadyInFaction = errors.New("house already belongs to a faction")
+	NotInFaction     = errors.New("house does not belong to a faction")
+	NotFactionLeader = errors.New("only the faction leader may declare war")
+	WarWithItself    = errors.New("faction can't declare war on itself")
+)
+
+type FactionId string
+
+/*
+Factions are coalitions of houses. Membership implies an alliance between every
+pair of members, and a war declared by the leader is shared by all of them.
+*/
+type Faction struct {
+	Id      FactionId
+	Name    string
+	Leader  families.HouseId   `toml:"leader"`
+	Members []families.HouseId `toml:"members"`
+	// factions this faction is at war with
+	Wars []FactionId `toml:"wars"`
+}
+
+type Factions map[FactionId]*Faction
+
+func (self *Faction) has(houseId families.HouseId) bool {
+	for _, member := range self.Members {
+		if member == houseId {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *Faction) atWarWith(factionId FactionId) bool {
+	for _, war := range self.Wars {
+		if war == factionId {
+			return true
+		}
+	}
+	return false
+}
+
+// FactionOf returns the faction the house belongs to, or nil if it has none.
+func (self *DiplomatsTable) FactionOf(houseId families.HouseId) *Faction {
+	for _, faction := range self.Factions {
+		if faction.has(houseId) {
+			return faction
+		}
+	}
+	return nil
+}
+
+func (self *DiplomatsTable) sameFaction(houseId1, houseId2 families.HouseId) bool {
+	f := self.FactionOf(houseId1)
+	return f != nil && f.has(houseId2)
+}
+
+func (self *DiplomatsTable) factionsAtWar(houseId1, houseId2 families.HouseId) bool {
+	f1 := self.FactionOf(houseId1)
+	f2 := self.FactionOf(houseId2)
+	if f1 == nil || f2 == nil {
+		return false
+	}
+	return f1.atWarWith(f2.Id)
+}
+
+func (self *DiplomatsTable) JoinFaction(factionId FactionId, houseId families.HouseId) error {
+	faction, ok := self.Factions[factionId]
+	if !ok {
+		return FactionNonexist
+	}
+	if _, ok := self.houses[houseId]; !ok {
+		return errors.New(fmt.Sprintf("house %v does not exist", houseId))
+	}
+	if self.FactionOf(houseId) != nil {
+		return AlreadyInFaction
+	}
+	faction.Members = append(faction.Members, houseId)
+	if faction.Leader == "" {
+		faction.Leader = houseId
+	}
+	self.applyFaction(faction)
+	return nil
+}
+
+// LeaveFaction removes the house from its faction. Its relations to the former
+// members and their enemies fall back to neutral. If the leader leaves, the
+// next member takes over, and a faction without members is dissolved.
+func (self *DiplomatsTable) LeaveFaction(houseId families.HouseId) error {
+	faction := self.FactionOf(houseId)
+	if faction == nil {
+		return NotInFaction
+	}
+	members := faction.Members[:0]
+	for _, member := range faction.Members {
+		if member != houseId {
+			members = append(members, member)
+		}
+	}
+	faction.Members = members
+	for _, member := range faction.Members {
+		self.setStatus(houseId, member, NEUTRAL)
+	}
+	for _, war := range faction.Wars {
+		if enemy, ok := self.Factions[war]; ok {
+			for _, member := range enemy.Members {
+				self.setStatus(houseId, member, NEUTRAL)
+			}
+		}
+	}
+	if len(faction.Members) == 0 {
+		self.dissolveFaction(faction)
+		return nil
+	}
+	if faction.Leader == houseId {
+		faction.Leader = faction.Members[0]
+	}
+	return nil
+}
+
+// DeclareWar is made by the leader of a faction on behalf of all its members.
+func (self *DiplomatsTable) DeclareWar(by families.HouseId, target FactionId) error {
+	faction := self.FactionOf(by)
+	if faction == nil {
+		return NotInFaction
+	}
+	if faction.Leader != by {
+		return NotFactionLeader
+	}
+	enemy, ok := self.Factions[target]
+	if !ok {
+		return FactionNonexist
+	}
+	if enemy.Id == faction.Id {
+		return WarWithItself
+	}
+	if !faction.atWarWith(enemy.Id) {
+		faction.Wars = append(faction.Wars, enemy.Id)
+	}
+	if !enemy.atWarWith(faction.Id) {
+		enemy.Wars = append(enemy.Wars, faction.Id)
+	}
+	self.applyFaction(faction)
+	return nil
+}
+
+func (self *DiplomatsTable) dissolveFaction(faction *Faction) {
+	for _, war := range faction.Wars {
+		if enemy, ok := self.Factions[war]; ok {
+			wars := enemy.Wars[:0]
+			for _, w := range enemy.Wars {
+				if w != faction.Id {
+					wars = append(wars, w)
+				}
+			}
+			enemy.Wars = wars
+		}
+	}
+	delete(self.Factions, faction.Id)
+}
+
+// pairwise relations are kept in step with faction membership so that the
+// relations table alone still describes the state of the realm.
+func (self *DiplomatsTable) applyFaction(faction *Faction) {
+	for _, h1 := range faction.Members {
+		for _, h2 := range faction.Members {
+			self.setStatus(h1, h2, ALLIED)
+		}
+		for _, war := range faction.Wars {
+			if enemy, ok := self.Factions[war]; ok {
+				for _, h2 := range enemy.Members {
+					self.setStatus(h1, h2, ENEMY)
+				}
+			}
+		}
+	}
+}
+
+func (self *DiplomatsTable) setStatus(houseId1, houseId2 families.HouseId, status OfficialStatus) {
+	if relation := self.relation(houseId1, houseId2); relation != nil {
+		relation.OfficialStatus = status
+	}
+}
+
+func (self *DiplomatsTable) initializeFactions() error {
+	membership := make(map[families.HouseId]FactionId)
+	for factionId, faction := range self.Factions {
+		faction.Id = factionId
+		for _, member := range faction.Members {
+			if _, ok := self.houses[member]; !ok {
+				return errors.New(fmt.Sprintf("faction %v member %v does not exist", factionId, member))
+			}
+			if other, ok := membership[member]; ok {
+				return errors.New(fmt.Sprintf("house %v belongs to factions %v and %v", member, other, factionId))
+			}
+			membership[member] = factionId
+		}
+		if faction.Leader == "" && len(faction.Members) > 0 {
+			faction.Leader = faction.Members[0]
+		}
+		if faction.Leader != "" && !faction.has(faction.Leader) {
+			return errors.New(fmt.Sprintf("faction %v leader %v is not a member", factionId, faction.Leader))
+		}
+	}
+	// wars are always shared by both sides
+	for factionId, faction := range self.Factions {
+		for _, war := range faction.Wars {
+			enemy, ok := self.Factions[war]
+			if !ok {
+				return errors.New(fmt.Sprintf("faction %v at war with nonexistent faction %v", factionId, war))
+			}
+			if enemy == faction {
+				return WarWithItself
+			}
+			if !enemy.atWarWith(factionId) {
+				enemy.Wars = append(enemy.Wars, factionId)
+			}
+		}
+	}
+	for _, faction := range self.Factions {
+		self.applyFaction(faction)
+	}
+	return nil
+}
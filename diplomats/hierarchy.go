@@ -0,0 +1,35 @@
+package diplomats
+
+import "github.com/pgruenbacher/got/families"
+
+// vassals inherit the diplomatic stance of the ruler at the top of their realm
+func (self *DiplomatsTable) ruler(houseId families.HouseId) families.HouseId {
+	if self.houses == nil {
+		return houseId
+	}
+	return self.houses.Ruler(houseId)
+}
+
+func (self *DiplomatsTable) Houses() families.Houses {
+	return self.houses
+}
+
+// Rebel breaks the vassal away from its liege, the two houses are at war afterwards.
+func (self *DiplomatsTable) Rebel(vassal families.HouseId) (families.RebellionEvent, error) {
+	e, err := self.houses.Rebel(vassal)
+	if err != nil {
+		return e, err
+	}
+	self.setStatus(vassal, e.Liege, ENEMY)
+	return e, nil
+}
+
+// SwearFealty changes the allegiance of the vassal, who is allied with its new liege afterwards.
+func (self *DiplomatsTable) SwearFealty(vassal, liege families.HouseId) (families.AllegianceEvent, error) {
+	e, err := self.houses.SwearFealty(vassal, liege)
+	if err != nil {
+		return e, err
+	}
+	self.setStatus(vassal, liege, ALLIED)
+	return e, nil
+}
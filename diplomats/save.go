@@ -0,0 +1,116 @@
+package diplomats
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/families"
+)
+
+// TableState is the table laid out for saving, each relation saved once.
+type TableState struct {
+	Relations []RelationState
+	Factions  Factions
+	Proposals []ProposalState
+}
+
+type RelationState struct {
+	House1         families.HouseId
+	House2         families.HouseId
+	OfficialStatus OfficialStatus
+	RelationStatus RelationStatus
+}
+
+type ProposalState struct {
+	From  families.HouseId
+	To    families.HouseId
+	Envoy characters.CharacterId
+}
+
+func (self *DiplomatsTable) Save() (s TableState) {
+	var houses []string
+	for houseId := range self.RelationsTable {
+		houses = append(houses, string(houseId))
+	}
+	sort.Strings(houses)
+	// relations are saved from the house that comes first, so that each is saved once
+	for _, h1 := range houses {
+		for _, h2 := range houses {
+			relation, ok := self.RelationsTable[families.HouseId(h1)][families.HouseId(h2)]
+			if !ok || h2 <= h1 {
+				continue
+			}
+			s.Relations = append(s.Relations, RelationState{
+				House1:         families.HouseId(h1),
+				House2:         families.HouseId(h2),
+				OfficialStatus: relation.OfficialStatus,
+				RelationStatus: relation.RelationStatus,
+			})
+		}
+	}
+	s.Factions = self.Factions
+	for _, p := range self.proposals {
+		state := ProposalState{From: p.from, To: p.to}
+		if p.envoy != nil {
+			state.Envoy = p.envoy.Id
+		}
+		s.Proposals = append(s.Proposals, state)
+	}
+	return s
+}
+
+// Restore rebuilds the table from the save, each relation shared again by its two houses.
+func (self *DiplomatsTable) Restore(s TableState, h families.Houses, c characters.Characters) error {
+	self.houses = h
+	self.RelationsTable = make(map[families.HouseId]Relations, len(h))
+	for houseId := range h {
+		self.RelationsTable[houseId] = make(Relations, len(h)-1)
+	}
+	for _, r := range s.Relations {
+		house1, ok1 := h[r.House1]
+		house2, ok2 := h[r.House2]
+		if !ok1 || !ok2 || r.House1 == r.House2 {
+			return errors.New(fmt.Sprintf("invalid relation between %v and %v", r.House1, r.House2))
+		}
+		relation := newRelation(house1, house2)
+		relation.OfficialStatus, relation.RelationStatus = r.OfficialStatus, r.RelationStatus
+		self.RelationsTable[r.House1][r.House2] = relation
+		self.RelationsTable[r.House2][r.House1] = relation
+	}
+	self.Factions = s.Factions
+	for factionId, faction := range self.Factions {
+		faction.Id = factionId
+		for _, member := range faction.Members {
+			if _, ok := h[member]; !ok {
+				return errors.New(fmt.Sprintf("faction %v member %v does not exist", factionId, member))
+			}
+		}
+	}
+	self.proposals = nil
+	for _, p := range s.Proposals {
+		envoy, ok := c[p.Envoy]
+		if !ok {
+			return errors.New(fmt.Sprintf("proposal envoy %v does not exist", p.Envoy))
+		}
+		self.proposals = append(self.proposals, NewProposal(p.From, p.To, envoy))
+	}
+	return self.Check()
+}
+
+// Check makes sure every pair of houses shares exactly one relation.
+func (self *DiplomatsTable) Check() error {
+	for h1 := range self.houses {
+		for h2 := range self.houses {
+			if h1 == h2 {
+				continue
+			}
+			relation, ok := self.RelationsTable[h1][h2]
+			if !ok || relation != self.RelationsTable[h2][h1] {
+				return errors.New(fmt.Sprintf("houses %v and %v don't share a relation", h1, h2))
+			}
+		}
+	}
+	return nil
+}
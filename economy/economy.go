@@ -0,0 +1,107 @@
+package economy
+
+import (
+	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
+)
+
+// Resources are yielded by regions, kept in the treasuries of houses, and spent on armies.
+type Resources struct {
+	Gold     int `toml:"gold"`
+	Food     int `toml:"food"`
+	Manpower int `toml:"manpower"`
+}
+
+func (self Resources) Add(other Resources) Resources {
+	return Resources{
+		Gold:     self.Gold + other.Gold,
+		Food:     self.Food + other.Food,
+		Manpower: self.Manpower + other.Manpower,
+	}
+}
+
+func (self Resources) Sub(other Resources) Resources {
+	return Resources{
+		Gold:     self.Gold - other.Gold,
+		Food:     self.Food - other.Food,
+		Manpower: self.Manpower - other.Manpower,
+	}
+}
+
+// Covers is true if there is enough of every resource to pay the cost.
+func (self Resources) Covers(cost Resources) bool {
+	return self.Gold >= cost.Gold && self.Food >= cost.Food && self.Manpower >= cost.Manpower
+}
+
+func (self Resources) InDeficit() bool {
+	return self.Gold < 0 || self.Food < 0 || self.Manpower < 0
+}
+
+// Deficit returns the shortfall of each resource, and the resources left once it is written off.
+func (self Resources) Deficit() (deficit Resources, left Resources) {
+	left = self
+	if self.Gold < 0 {
+		deficit.Gold, left.Gold = -self.Gold, 0
+	}
+	if self.Food < 0 {
+		deficit.Food, left.Food = -self.Food, 0
+	}
+	if self.Manpower < 0 {
+		deficit.Manpower, left.Manpower = -self.Manpower, 0
+	}
+	return deficit, left
+}
+
+type Treasuries map[families.HouseId]*Resources
+
+// Clone copies the treasuries, a world without treasuries stays without.
+func (self Treasuries) Clone() Treasuries {
+	if self == nil {
+		return nil
+	}
+	clone := make(Treasuries, len(self))
+	for houseId, treasury := range self {
+		t := *treasury
+		clone[houseId] = &t
+	}
+	return clone
+}
+
+// Of returns the treasury of the house, opening an empty one if it has none.
+func (self Treasuries) Of(houseId families.HouseId) *Resources {
+	treasury, ok := self[houseId]
+	if !ok {
+		treasury = new(Resources)
+		self[houseId] = treasury
+	}
+	return treasury
+}
+
+// the treasury of a house after a turn of income, tribute and upkeep
+type IncomeEvent struct {
+	events.Event
+	HouseId  families.HouseId
+	Income   Resources
+	Upkeep   Resources
+	Tribute  int
+	Deficit  Resources
+	Treasury Resources
+}
+
+func NewIncomeEvent(houseId families.HouseId) IncomeEvent {
+	return IncomeEvent{
+		Event:   events.NewEvent(),
+		HouseId: houseId,
+	}
+}
+
+var ExampleTreasuries = `
+    [house1]
+    gold = 10
+    food = 10
+    manpower = 20
+    [house2]
+    gold = 20
+    food = 5
+    manpower = 10
+`
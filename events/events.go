@@ -4,6 +4,8 @@ import "github.com/pgruenbacher/got/utils"
 
 type Event struct {
 	Id string
+	// houses the event is disclosed to, everyone if empty
+	Audience []string
 }
 
 func NewEvent() Event {
@@ -12,4 +14,36 @@ func NewEvent() Event {
 	}
 }
 
+// Restrict hides the event from everyone but the given houses.
+func (self *Event) Restrict(houses ...string) {
+	self.Audience = append(self.Audience, houses...)
+}
+
+func (self Event) VisibleTo(house string) bool {
+	if len(self.Audience) == 0 {
+		return true
+	}
+	for _, h := range self.Audience {
+		if h == house {
+			return true
+		}
+	}
+	return false
+}
+
 type EventsInterface interface{}
+
+// Disclosed events may be hidden from some houses.
+type Disclosed interface {
+	VisibleTo(house string) bool
+}
+
+// Visible leaves out the events hidden from the house.
+func Visible(house string, e []EventsInterface) (visible []EventsInterface) {
+	for _, event := range e {
+		if d, ok := event.(Disclosed); !ok || d.VisibleTo(house) {
+			visible = append(visible, event)
+		}
+	}
+	return visible
+}
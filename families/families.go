@@ -1,17 +1,56 @@
 package families
 
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/validator.v2"
+)
+
 type House struct {
 	Id   HouseId
 	Name string
+	// Liege is the house this house has sworn fealty to, empty for independent houses.
+	Liege HouseId `toml:"liege"`
+	// Obligations owed to the liege.
+	// Levy is the fraction of the vassal's army strength the liege may call upon.
+	Levy float32 `toml:"levy" validate:"min=0,max=1"`
+	// Tribute is paid to the liege every turn.
+	Tribute int `toml:"tribute" validate:"min=0"`
 }
 type HouseId string
 
 type Houses map[HouseId]*House
 
-func (self Houses) InitializeAll() {
+func (self Houses) InitializeAll() error {
 	for houseId, house := range self {
+		if err := validator.Validate(house); err != nil {
+			return err
+		}
 		house.Id = houseId
 	}
+	for houseId, house := range self {
+		if house.Liege == "" {
+			continue
+		}
+		if _, ok := self[house.Liege]; !ok {
+			return errors.New(fmt.Sprintf("house %v liege %v does not exist", houseId, house.Liege))
+		}
+		if self.inRealmOf(house.Liege, houseId) {
+			return errors.New(fmt.Sprintf("house %v fealty is cyclic", houseId))
+		}
+	}
+	return nil
+}
+
+// Clone copies the houses, so that their allegiances may change without touching the original.
+func (self Houses) Clone() Houses {
+	clone := make(Houses, len(self))
+	for houseId, house := range self {
+		h := *house
+		clone[houseId] = &h
+	}
+	return clone
 }
 
 var ExampleHouses = `
@@ -24,3 +63,20 @@ var ExampleHouses = `
     [house4]
     name="tyrell"
 `
+
+// the houses of ExampleHouses, with mormont sworn to stark
+var ExampleVassals = `
+    [house1]
+    name="stark"
+    [house2]
+    name="lannister"
+    [house3]
+    name="mormont"
+    liege="house1"
+    levy=0.5
+    tribute=2
+    [house4]
+    name="tyrell"
+    [house5]
+    name="florent"
+`
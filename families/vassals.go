@@ -0,0 +1,143 @@
+package families
+
+import (
+	"errors"
+
+	"github.com/pgruenbacher/got/events"
+)
+
+var (
+	NoLiege        = errors.New("house has no liege")
+	HouseNonexist  = errors.New("house does not exist")
+	CyclicFealty   = errors.New("house can't swear fealty to its own vassal")
+	FealtyToItself = errors.New("house can't swear fealty to itself")
+)
+
+// Events
+type HouseEvent struct {
+	events.Event
+	HouseId HouseId
+}
+
+// a vassal throws off its liege and becomes independent
+type RebellionEvent struct {
+	HouseEvent
+	Liege HouseId
+}
+
+// a house swears fealty to a new liege. From is empty if the house was independent.
+type AllegianceEvent struct {
+	HouseEvent
+	From HouseId
+	To   HouseId
+}
+
+// Vassals returns the direct vassals of the house.
+func (self Houses) Vassals(houseId HouseId) (vassals []*House) {
+	for _, house := range self {
+		if house.Liege == houseId {
+			vassals = append(vassals, house)
+		}
+	}
+	return vassals
+}
+
+// Ruler follows the chain of lieges up to the independent house at the top of the realm.
+func (self Houses) Ruler(houseId HouseId) HouseId {
+	current := houseId
+	for i := 0; i <= len(self); i++ {
+		house, ok := self[current]
+		if !ok || house.Liege == "" {
+			return current
+		}
+		current = house.Liege
+	}
+	// cyclic fealty is rejected on init, but don't loop forever on corrupted data
+	return houseId
+}
+
+// SameRealm is true if both houses answer to the same ruler.
+func (self Houses) SameRealm(houseId1, houseId2 HouseId) bool {
+	return self.Ruler(houseId1) == self.Ruler(houseId2)
+}
+
+// wether the house is the liege or is somewhere below the liege in the hierarchy
+func (self Houses) inRealmOf(houseId, liege HouseId) bool {
+	current := houseId
+	for i := 0; i <= len(self); i++ {
+		if current == liege {
+			return true
+		}
+		house, ok := self[current]
+		if !ok || house.Liege == "" {
+			return false
+		}
+		current = house.Liege
+	}
+	return false
+}
+
+func (self Houses) SwearFealty(vassal, liege HouseId) (e AllegianceEvent, err error) {
+	house, ok := self[vassal]
+	if !ok {
+		return e, HouseNonexist
+	}
+	if _, ok := self[liege]; !ok {
+		return e, HouseNonexist
+	}
+	if vassal == liege {
+		return e, FealtyToItself
+	}
+	if self.inRealmOf(liege, vassal) {
+		return e, CyclicFealty
+	}
+	e = AllegianceEvent{
+		HouseEvent: newHouseEvent(vassal),
+		From:       house.Liege,
+		To:         liege,
+	}
+	house.Liege = liege
+	return e, nil
+}
+
+func (self Houses) Rebel(vassal HouseId) (e RebellionEvent, err error) {
+	house, ok := self[vassal]
+	if !ok {
+		return e, HouseNonexist
+	}
+	if house.Liege == "" {
+		return e, NoLiege
+	}
+	e = RebellionEvent{
+		HouseEvent: newHouseEvent(vassal),
+		Liege:      house.Liege,
+	}
+	house.Liege = ""
+	return e, nil
+}
+
+// Remove takes the house out of the realm. Its vassals swear fealty to the
+// successor, or become independent if there is none.
+func (self Houses) Remove(houseId, successor HouseId) (events []AllegianceEvent) {
+	for _, vassal := range self.Vassals(houseId) {
+		if vassal.Id == successor {
+			vassal.Liege = ""
+		} else {
+			vassal.Liege = successor
+		}
+		events = append(events, AllegianceEvent{
+			HouseEvent: newHouseEvent(vassal.Id),
+			From:       houseId,
+			To:         vassal.Liege,
+		})
+	}
+	delete(self, houseId)
+	return events
+}
+
+func newHouseEvent(id HouseId) HouseEvent {
+	return HouseEvent{
+		Event:   events.NewEvent(),
+		HouseId: id,
+	}
+}
@@ -0,0 +1,30 @@
+package game
+
+// Clone copies the whole realm, nothing done to the copy reaches the original.
+// The battles of the copy are fought with the seed.
+func (self *Game) Clone(seed int64) *Game {
+	clone := *self
+	clone.Houses = self.Houses.Clone()
+	clone.Regions = self.Regions.Clone()
+	clone.Characters = self.Characters.Clone()
+	clone.Treasuries = self.Treasuries.Clone()
+	clone.Diplomacy = self.Diplomacy.Clone(clone.Houses, clone.Characters)
+	clone.Armies = self.Armies.Clone(clone.Regions, &clone.Diplomacy, clone.Characters, clone.Treasuries, seed)
+	return &clone
+}
+
+// Snapshot is the realm frozen at a moment, from which any number of branches may be played out.
+type Snapshot struct {
+	game *Game
+}
+
+// the snapshot fights no battles, its branches are seeded as they are restored
+func (self *Game) Snapshot() Snapshot {
+	return Snapshot{self.Clone(0)}
+}
+
+// Restore gives a game starting from the snapshot, the snapshot itself is left as it was.
+// Branches restored with the same seed fight their battles alike.
+func (self Snapshot) Restore(seed int64) *Game {
+	return self.game.Clone(seed)
+}
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/pgruenbacher/got/economy"
+	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
+)
+
+// Income is the yield of the regions the house owns and controls, occupied regions yield nothing.
+func (self *Game) Income(houseId families.HouseId) (income economy.Resources) {
+	for _, region := range self.Regions.OwnedBy(houseId) {
+		if region.Controller == houseId {
+			income = income.Add(region.Yield)
+		}
+	}
+	return income
+}
+
+// CollectIncome fills every treasury with the income of the house, pays the
+// upkeep of the armies and the tribute owed to lieges. The armies of houses
+// that can't pay their upkeep suffer for it.
+func (self *Game) CollectIncome() (e []events.EventsInterface) {
+	for _, houseId := range self.houseIds() {
+		house := self.Houses[houseId]
+		treasury := self.Treasuries.Of(houseId)
+		income := economy.NewIncomeEvent(houseId)
+		income.Income = self.Income(houseId)
+		income.Upkeep = self.Armies.Upkeep(houseId)
+		left := treasury.Add(income.Income).Sub(income.Upkeep)
+		// tribute is paid out of what is left, vassals aren't ruined by it
+		if _, ok := self.Houses[house.Liege]; ok && house.Tribute > 0 && left.Gold > 0 {
+			income.Tribute = house.Tribute
+			if income.Tribute > left.Gold {
+				income.Tribute = left.Gold
+			}
+			left.Gold = left.Gold - income.Tribute
+			self.Treasuries.Of(house.Liege).Gold += income.Tribute
+		}
+		income.Deficit, left = left.Deficit()
+		*treasury = left
+		income.Treasury = left
+		e = append(e, income)
+		for _, event := range self.Armies.ApplyDeficit(houseId, income.Deficit) {
+			e = append(e, event)
+		}
+	}
+	return e
+}
+
+func (self *Game) houseIds() (houseIds []families.HouseId) {
+	var ids []string
+	for houseId := range self.Houses {
+		ids = append(ids, string(houseId))
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		houseIds = append(houseIds, families.HouseId(id))
+	}
+	return houseIds
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"github.com/pgruenbacher/got/armies"
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/diplomats"
+	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
+	"github.com/pgruenbacher/got/regions"
+)
+
+type EliminationCause string
+
+const (
+	// the house holds no regions and has no armies left
+	CONQUERED EliminationCause = "CONQUERED"
+	// no one is left in the line of succession, free or captured
+	EXTINCT EliminationCause = "EXTINCT"
+)
+
+// a house was eliminated, its armies and regions passed to the successor or became neutral
+type EliminationEvent struct {
+	events.Event
+	HouseId   families.HouseId
+	Successor families.HouseId
+	Cause     EliminationCause
+	Armies    []armies.TransferEvent
+	Regions   []regions.OwnerEvent
+	Diplomacy diplomats.RemovalEvent
+}
+
+// CheckEliminations settles the succession of houses whose head has died, and
+// eliminates houses that are extinct or have nothing left to hold.
+func (self *Game) CheckEliminations() (e []events.EventsInterface) {
+	for _, houseId := range self.houseIds() {
+		if self.Characters.NeedsSuccession(houseId) {
+			succession, err := self.Characters.Succeed(houseId)
+			if err == characters.NoSuccessor && len(self.Characters.Captives(houseId)) == 0 {
+				e = append(e, self.Eliminate(houseId, EXTINCT))
+				continue
+			}
+			// a house whose line lives on in captivity waits for a release
+			if err == nil {
+				e = append(e, succession)
+			}
+		}
+		if len(self.Armies.Armies.OfHouse(houseId)) == 0 && len(self.Regions.OwnedBy(houseId)) == 0 {
+			e = append(e, self.Eliminate(houseId, CONQUERED))
+		}
+	}
+	return e
+}
+
+// Eliminate removes the house from the game. Everything it held, its treasury
+// included, passes to its liege, or becomes neutral if it was independent.
+func (self *Game) Eliminate(houseId families.HouseId, cause EliminationCause) EliminationEvent {
+	var successor families.HouseId
+	if house, ok := self.Houses[houseId]; ok {
+		successor = house.Liege
+	}
+	if treasury, ok := self.Treasuries[houseId]; ok {
+		if successor != "" {
+			*self.Treasuries.Of(successor) = self.Treasuries.Of(successor).Add(*treasury)
+		}
+		delete(self.Treasuries, houseId)
+	}
+	return EliminationEvent{
+		Event:     events.NewEvent(),
+		HouseId:   houseId,
+		Successor: successor,
+		Cause:     cause,
+		Armies:    self.Armies.TransferArmies(houseId, successor),
+		Regions:   self.Regions.Transfer(houseId, successor),
+		Diplomacy: self.Diplomacy.RemoveHouse(houseId, successor),
+	}
+}
@@ -0,0 +1,113 @@
+package game
+
+import (
+	"github.com/BurntSushi/toml"
+	"github.com/pgruenbacher/got/armies"
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/diplomats"
+	"github.com/pgruenbacher/got/economy"
+	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
+	"github.com/pgruenbacher/got/regions"
+)
+
+/*
+Game holds the whole state of the realm, and coordinates the rules that reach
+across houses, regions, armies and diplomacy.
+*/
+type Game struct {
+	Turn       int
+	Houses     families.Houses
+	Regions    regions.Regions
+	Diplomacy  diplomats.DiplomatsTable
+	Characters characters.Characters
+	Armies     armies.ArmiesManager
+	Treasuries economy.Treasuries
+}
+
+// Init connects the decoded scenario together, the armies are placed last.
+func (self *Game) Init(a armies.Armies) error {
+	if err := self.Houses.InitializeAll(); err != nil {
+		return err
+	}
+	if err := self.Regions.ConnectAll(); err != nil {
+		return err
+	}
+	if err := self.Diplomacy.Init(self.Houses); err != nil {
+		return err
+	}
+	if self.Treasuries == nil {
+		self.Treasuries = make(economy.Treasuries, len(self.Houses))
+	}
+	for houseId := range self.Houses {
+		self.Treasuries.Of(houseId)
+	}
+	if err := self.Characters.InitializeAll(self.Houses); err != nil {
+		return err
+	}
+	if err := self.Armies.Init(a, self.Regions, &self.Diplomacy); err != nil {
+		return err
+	}
+	self.Armies.InitEconomy(self.Treasuries)
+	return self.Armies.InitCharacters(self.Characters)
+}
+
+// Example builds the realm of the package examples, with the example army config.
+func Example() (*Game, error) {
+	var g Game
+	var a armies.Armies
+	examples := []struct {
+		data string
+		v    interface{}
+	}{
+		{families.ExampleVassals, &g.Houses},
+		{regions.ExampleRegions, &g.Regions},
+		{diplomats.ExampleVassalTable, &g.Diplomacy},
+		{characters.ExampleCharacters, &g.Characters},
+		{armies.SampleArmies, &a},
+		{armies.ExampleModifiers, &g.Armies.Config},
+	}
+	for _, example := range examples {
+		if _, err := toml.Decode(example.data, example.v); err != nil {
+			return nil, err
+		}
+	}
+	if err := g.Init(a); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// EndTurn settles the state of the realm after the orders of the turn are resolved.
+// The events are the whole log of the turn, Disclose splits them by house.
+func (self *Game) EndTurn() (e []events.EventsInterface, err error) {
+	for _, event := range self.Armies.EvalMusters() {
+		e = append(e, event)
+	}
+	for _, event := range self.Armies.EvalSieges() {
+		e = append(e, event)
+	}
+	for _, event := range self.Armies.EvalOccupation() {
+		e = append(e, event)
+	}
+	e = append(e, self.CollectIncome()...)
+	if err := self.Armies.EvaluateArmies(); err != nil {
+		return e, err
+	}
+	for _, event := range self.Armies.EvalRecovery() {
+		e = append(e, event)
+	}
+	self.Armies.EndBattles()
+	e = append(e, self.CheckEliminations()...)
+	self.Turn++
+	return e, nil
+}
+
+// Disclose hands each house the events it may learn of.
+func (self *Game) Disclose(e []events.EventsInterface) map[families.HouseId][]events.EventsInterface {
+	disclosed := make(map[families.HouseId][]events.EventsInterface, len(self.Houses))
+	for houseId := range self.Houses {
+		disclosed[houseId] = events.Visible(string(houseId), e)
+	}
+	return disclosed
+}
@@ -0,0 +1,549 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pgruenbacher/got/armies"
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/diplomats"
+	"github.com/pgruenbacher/got/economy"
+	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
+	"github.com/pgruenbacher/got/regions"
+)
+
+func exampleGame(t *testing.T) *Game {
+	var g Game
+	if _, err := toml.Decode(families.ExampleVassals, &g.Houses); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := toml.Decode(regions.ExampleRegions, &g.Regions); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := toml.Decode(diplomats.ExampleVassalTable, &g.Diplomacy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := toml.Decode(characters.ExampleCharacters, &g.Characters); err != nil {
+		t.Fatal(err)
+	}
+	var a armies.Armies
+	if _, err := toml.Decode(armies.SampleArmies, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Init(a); err != nil {
+		t.Fatal(err)
+	}
+	return &g
+}
+
+func TestElimination(t *testing.T) {
+	g := exampleGame(t)
+	if e := g.CheckEliminations(); len(e) != 0 {
+		t.Error("no house should be eliminated at the start", e)
+	}
+	g.Characters["jaime"].Kill("house1")
+	e := g.CheckEliminations()
+	if len(e) != 1 || g.Characters.Head("house2").Id != "kevan" {
+		t.Error("kevan should succeed jaime", e)
+	}
+	g.Characters["kevan"].Kill("house1")
+	e = g.CheckEliminations()
+	if len(e) != 1 {
+		t.Error("house2 should be extinct", e)
+		return
+	}
+	elimination := e[0].(EliminationEvent)
+	if elimination.Cause != EXTINCT || elimination.Successor != "" || len(elimination.Armies) != 1 {
+		t.Error("unexpected elimination", elimination)
+	}
+	if _, ok := g.Houses["house2"]; ok {
+		t.Error("house2 should be removed")
+	}
+	if _, ok := g.Diplomacy.RelationsTable["house1"]["house2"]; ok {
+		t.Error("relations of house2 should be cleared")
+	}
+	if g.Armies.Armies["army2"].House != "" || g.Regions["region3"].Owner != "" {
+		t.Error("armies and regions of house2 should become neutral")
+	}
+}
+
+func TestCapturedLine(t *testing.T) {
+	g := exampleGame(t)
+	g.Characters["kevan"].Capture("house1")
+	g.Characters["jaime"].Kill("house1")
+	if e := g.CheckEliminations(); len(e) != 0 {
+		t.Error("house2 should live on while kevan is held", e)
+	}
+	if _, ok := g.Houses["house2"]; !ok || g.Characters.Head("house2") != nil {
+		t.Error("house2 should be left without a head")
+	}
+	g.Characters["kevan"].Release()
+	e := g.CheckEliminations()
+	if len(e) != 1 || g.Characters.Head("house2") == nil || g.Characters.Head("house2").Id != "kevan" {
+		t.Error("kevan should succeed jaime once released", e)
+	}
+}
+
+func TestDisclose(t *testing.T) {
+	g := exampleGame(t)
+	var hidden armies.SiegeEvent
+	hidden.Restrict("house2")
+	disclosed := g.Disclose([]events.EventsInterface{hidden, armies.SiegeEvent{}})
+	if len(disclosed["house1"]) != 1 || len(disclosed["house2"]) != 2 {
+		t.Error("house1 should only learn of the open event", disclosed)
+	}
+}
+
+func TestOccupation(t *testing.T) {
+	g := exampleGame(t)
+	if _, err := toml.Decode(armies.ExampleModifiers, &g.Armies.Config); err != nil {
+		t.Fatal(err)
+	}
+	// army1 of house1 stands in region3cost of house2, and army2 in region1 of house1
+	if _, err := g.EndTurn(); err != nil {
+		t.Error(err)
+	}
+	if g.Regions["region3cost"].Controller != "house1" || g.Regions["region3cost"].Owner != "house2" {
+		t.Error("army1 should occupy region3cost", g.Regions["region3cost"])
+	}
+	if _, err := g.EndTurn(); err != nil {
+		t.Error(err)
+	}
+	if g.Regions["region3cost"].Owner != "house1" || g.Regions["region1"].Owner != "house2" {
+		t.Error("regions should be conquered after two turns")
+	}
+}
+
+func TestSiege(t *testing.T) {
+	g := exampleGame(t)
+	castle := g.Regions["region7"].Castle
+	g.Armies.Armies["army1"].Region = g.Regions["region7"]
+	for turn := 0; turn < 4; turn++ {
+		if _, err := g.EndTurn(); err != nil {
+			t.Error(err)
+		}
+		if g.Regions["region7"].Controller != "house2" {
+			t.Error("region7 can't be occupied while the castle holds")
+		}
+	}
+	if castle.Besieger != "house1" || castle.Garrison >= 20 {
+		t.Error("castle should be besieged and starving", castle)
+	}
+	garrison := castle.Garrison
+	assault := armies.AssaultOrder{Region: "region7"}
+	assault.ArmyId = "army1"
+	if _, err := g.Armies.ReadOrders([]armies.AssaultOrder{assault}); err != nil {
+		t.Error(err)
+	}
+	if castle.Walls != 3 || (castle.Garrison >= garrison && castle.Holds()) {
+		t.Error("assault should damage the walls and garrison", castle)
+	}
+	g.Armies.Armies["army1"].Region = g.Regions["region6"]
+	if _, err := g.EndTurn(); err != nil {
+		t.Error(err)
+	}
+	if castle.Holds() && castle.Besieged() {
+		t.Error("siege should be lifted once the besiegers leave")
+	}
+}
+
+func TestCastleFalls(t *testing.T) {
+	g := exampleGame(t)
+	castle := g.Regions["region7"].Castle
+	g.Armies.Armies["army1"].Region = g.Regions["region7"]
+	for turn := 0; turn <= castle.Provisions; turn++ {
+		if _, err := g.EndTurn(); err != nil {
+			t.Error(err)
+		}
+	}
+	castle.Garrison = 1
+	e, err := g.EndTurn()
+	if err != nil {
+		t.Error(err)
+	}
+	var fallen *armies.SiegeEvent
+	for _, event := range e {
+		if siege, ok := event.(armies.SiegeEvent); ok && siege.Ctx == armies.CASTLE_FALLEN {
+			fallen = &siege
+		}
+	}
+	if fallen == nil || len(fallen.Ownership) == 0 {
+		t.Fatal("the fallen castle should report the region changing hands", e)
+	}
+	if o := fallen.Ownership[0]; o.Ctx != regions.OCCUPIED || o.To != "house1" {
+		t.Error("region7 should be occupied by house1", o)
+	}
+	if g.Regions["region7"].Controller != "house1" {
+		t.Error("region7 should be controlled by house1")
+	}
+}
+
+func TestAssaultRepelled(t *testing.T) {
+	g := exampleGame(t)
+	castle := g.Regions["region7"].Castle
+	army1 := g.Armies.Armies["army1"]
+	army1.Region = g.Regions["region7"]
+	if _, err := g.EndTurn(); err != nil {
+		t.Error(err)
+	}
+	army1.Size, army1.Composition = 1, nil
+	assault := armies.AssaultOrder{Region: "region7"}
+	assault.ArmyId = "army1"
+	e, err := g.Armies.ReadOrders([]armies.AssaultOrder{assault})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if siege := e.([]armies.SiegeEvent)[0]; siege.Destroyed == nil || siege.Destroyed.ArmyId != "army1" {
+		t.Error("the assault should report army1 wiped out", siege)
+	}
+	if _, ok := g.Armies.Armies["army1"]; ok {
+		t.Error("army1 should be removed")
+	}
+	if _, err := g.EndTurn(); err != nil {
+		t.Error(err)
+	}
+	if castle.Besieged() {
+		t.Error("the siege should be lifted once the besiegers are gone")
+	}
+}
+
+func TestIncome(t *testing.T) {
+	g := exampleGame(t)
+	if _, err := toml.Decode(armies.ExampleModifiers, &g.Armies.Config); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := toml.Decode(economy.ExampleTreasuries, &g.Treasuries); err != nil {
+		t.Fatal(err)
+	}
+	g.Treasuries["house2"].Food = 0
+	g.CollectIncome()
+	// house1 yields region1, and pays 2 gold and 2 food for army1
+	if *g.Treasuries["house1"] != (economy.Resources{Gold: 11, Food: 12, Manpower: 25}) {
+		t.Error("unexpected treasury for house1", g.Treasuries["house1"])
+	}
+	// house2 yields region3 and region7, and can't feed army2 which pays 2 gold and 2 food
+	if g.Treasuries["house2"].Food != 2 {
+		t.Error("unexpected food for house2", g.Treasuries["house2"])
+	}
+	g.Treasuries["house2"].Food = 0
+	g.Regions["region3"].Controller = "house1"
+	g.Regions["region7"].Controller = "house1"
+	g.CollectIncome()
+	if g.Armies.Armies["army2"].Size != 27 {
+		t.Error("army2 should suffer desertion", g.Armies.Armies["army2"].Size)
+	}
+}
+
+func TestRecruitment(t *testing.T) {
+	g := exampleGame(t)
+	if _, err := toml.Decode(armies.ExampleModifiers, &g.Armies.Config); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := toml.Decode(economy.ExampleTreasuries, &g.Treasuries); err != nil {
+		t.Fatal(err)
+	}
+	g.Armies.InitEconomy(g.Treasuries)
+	order := armies.RecruitOrder{House: "house1", Region: "region1", Size: 30, Quality: 3}
+	if _, err := g.Armies.ReadOrders([]armies.RecruitOrder{order}); err == nil {
+		t.Error("region1 manpower should only support 20 recruits")
+	}
+	order.Size = 20
+	treasury := *g.Treasuries["house1"]
+	foreign := armies.RecruitOrder{House: "house1", Region: "region7", Size: 10, Quality: 3}
+	if _, err := g.Armies.ReadOrders([]armies.RecruitOrder{order, foreign}); err == nil {
+		t.Error("house1 can't recruit in region7")
+	}
+	if *g.Treasuries["house1"] != treasury {
+		t.Error("a refused batch of orders should cost nothing", g.Treasuries["house1"])
+	}
+	if _, err := g.Armies.ReadOrders([]armies.RecruitOrder{order}); err != nil {
+		t.Error(err)
+	}
+	if g.Treasuries["house1"].Gold != 4 || g.Treasuries["house1"].Manpower != 0 {
+		t.Error("recruitment should be paid from the treasury", g.Treasuries["house1"])
+	}
+	g.Armies.EvalMusters()
+	if _, ok := g.Armies.Armies["house1-army1"]; ok {
+		t.Error("army should still be mustering")
+	}
+	g.Armies.EvalMusters()
+	if army, ok := g.Armies.Armies["house1-army1"]; !ok || army.Region != g.Regions["region1"] {
+		t.Error("army should have mustered in region1")
+	}
+}
+
+func TestMergeSplit(t *testing.T) {
+	g := exampleGame(t)
+	split := armies.SplitOrder{Size: 10}
+	split.ArmyId = "army1"
+	if _, err := g.Armies.ReadOrders([]armies.SplitOrder{split}); err != nil {
+		t.Error(err)
+	}
+	army1, army2 := g.Armies.Armies["army1"], g.Armies.Armies["house1-army1"]
+	if army1.Size != 20 || army2 == nil || army2.Size != 10 || army2.Region != army1.Region {
+		t.Error("army1 should split off an army of 10")
+		return
+	}
+	army2.Morale, army2.Quality = 1, 1
+	merge := armies.MergeOrder{}
+	merge.ArmyId = "army1"
+	merge.Others = append(merge.Others, "house1-army1")
+	if _, err := g.Armies.ReadOrders([]armies.MergeOrder{merge}); err != nil {
+		t.Error(err)
+	}
+	// morale (20*3 + 10*1) / 30 and quality likewise
+	if _, ok := g.Armies.Armies["house1-army1"]; ok || army1.Size != 30 || army1.Morale != 2 || army1.Quality != 2 {
+		t.Error("armies should merge back with averaged morale and quality", army1)
+	}
+	if army1.Composition[armies.INFANTRY] != 20 || army1.Composition[armies.CAVALRY] != 10 {
+		t.Error("composition should be whole again", army1.Composition)
+	}
+}
+
+func TestMergeRefused(t *testing.T) {
+	g := exampleGame(t)
+	split := armies.SplitOrder{Size: 10}
+	split.ArmyId = "army1"
+	if _, err := g.Armies.ReadOrders([]armies.SplitOrder{split}); err != nil {
+		t.Fatal(err)
+	}
+	split.Size = 5
+	again := armies.SplitOrder{Size: 5}
+	again.ArmyId = "house1-army1"
+	if _, err := g.Armies.ReadOrders([]armies.SplitOrder{split, again}); err != nil {
+		t.Fatal(err)
+	}
+	// army1 of 15 and three armies of 5, one made too large to merge
+	g.Armies.Armies["house1-army2"].Size = 99
+	merge, large := armies.MergeOrder{}, armies.MergeOrder{}
+	merge.ArmyId = "army1"
+	merge.Others = append(merge.Others, "house1-army1")
+	large.ArmyId = "house1-army2"
+	large.Others = append(large.Others, "house1-army3")
+	if _, err := g.Armies.ReadOrders([]armies.MergeOrder{merge, large}); err == nil {
+		t.Error("an army of 104 is too large")
+	}
+	if _, ok := g.Armies.Armies["house1-army1"]; !ok || g.Armies.Armies["army1"].Size != 15 {
+		t.Error("a refused batch of merges should leave every army as it was")
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	g := exampleGame(t)
+	if _, err := toml.Decode(armies.ExampleModifiers, &g.Armies.Config); err != nil {
+		t.Fatal(err)
+	}
+	army1, army2 := g.Armies.Armies["army1"], g.Armies.Armies["army2"]
+	army1.Region, army1.Morale, army1.Size = g.Regions["region1"], 1, 20
+	g.Treasuries["house1"].Manpower = 4
+	if err := g.Armies.EvaluateArmies(); err != nil {
+		t.Error(err)
+	}
+	g.Armies.EvalRecovery()
+	if army1.Morale != 1 || army1.Size != 20 {
+		t.Error("army1 can't rest with army2 in region1")
+	}
+	army2.Region = g.Regions["region3"]
+	g.Armies.EvalRecovery()
+	// eddard rallies the army at home up to its cap, the house has only 4 men to spare
+	if army1.Morale != 5 || army1.Size != 24 || g.Treasuries["house1"].Manpower != 0 {
+		t.Error("army1 should recover at home", army1.Morale, army1.Size)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	g, err := Example()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var orders armies.Orders
+	if _, err := toml.Decode(armies.ExampleOrders, &orders); err != nil {
+		t.Fatal(err)
+	}
+	if preview := g.Armies.Preview("house1", orders, 1); len(preview.Warnings) != 0 {
+		t.Error("the example orders should all be given", preview.Warnings)
+	}
+	// army2 of house2 is out of sight of house1, its march can't be ordered
+	march := armies.MarchOrder{Src: "region1", Dst: "region2cost", Ctx: armies.MARCH}
+	march.ArmyId = "army2"
+	orders.March = append(orders.March, march)
+	preview := g.Armies.Preview("house1", orders, 1)
+	if len(preview.Warnings) != 1 {
+		t.Error("the order for army2 should be refused", preview.Warnings)
+	}
+	// the stance and the march of army1
+	if len(preview.Events) != 2 {
+		t.Error("expected the events of army1", preview.Events)
+	}
+	army1 := g.Armies.Armies["army1"]
+	if army1.Stance != "" || army1.Region.Id != "region3cost" {
+		t.Error("the preview should not change army1", army1)
+	}
+	if _, ok := g.Armies.Armies["army2"]; !ok {
+		t.Error("the preview should not hide army2 from the game")
+	}
+}
+
+func TestClone(t *testing.T) {
+	g, err := Example()
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := g.Snapshot()
+	clone := snapshot.Restore(1)
+	clone.Turn++
+	clone.Regions["region1"].Controller = "house2"
+	clone.Regions["region7"].Castle.Garrison = 0
+	clone.Diplomacy.RelationsTable["house1"]["house2"].OfficialStatus = diplomats.ALLIED
+	clone.Treasuries.Of("house1").Gold = 1000
+	clone.Characters["eddard"].Kill("house2")
+	clone.Houses["house3"].Liege = ""
+	army := clone.Armies.Armies["army1"]
+	army.Size = 1
+	army.March(army.Region.Edges["region2cost"])
+
+	if g.Turn != 0 || g.Regions["region1"].Controller == "house2" || g.Regions["region7"].Castle.Garrison == 0 {
+		t.Error("the regions of the game should be untouched")
+	}
+	if !g.Diplomacy.IsEnemy("house1", "house2") || g.Treasuries.Of("house1").Gold == 1000 {
+		t.Error("diplomacy and treasuries of the game should be untouched")
+	}
+	if !g.Characters["eddard"].Available() || g.Houses["house3"].Liege != "house1" {
+		t.Error("characters and houses of the game should be untouched")
+	}
+	if original := g.Armies.Armies["army1"]; original.Size == 1 || original.Region.Id != "region3cost" {
+		t.Error("army1 of the game should be untouched", original)
+	}
+	// the copy holds together on its own
+	if army.Region != clone.Regions["region2cost"] || army.Home != clone.Regions[army.Home.Id] {
+		t.Error("army1 should stand in the regions of the copy")
+	}
+	if clone.Diplomacy.RelationsTable["house2"]["house1"].OfficialStatus != diplomats.ALLIED {
+		t.Error("both houses should share their relation in the copy")
+	}
+	if restored := snapshot.Restore(1); restored.Turn != 0 || restored.Armies.Armies["army1"].Size == 1 {
+		t.Error("the snapshot should be untouched by its branches")
+	}
+}
+
+func BenchmarkClone(b *testing.B) {
+	g, err := Example()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.Clone(int64(i))
+	}
+}
+
+func BenchmarkPreview(b *testing.B) {
+	g, err := Example()
+	if err != nil {
+		b.Fatal(err)
+	}
+	var orders armies.Orders
+	if _, err := toml.Decode(armies.ExampleOrders, &orders); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.Armies.Preview("house1", orders, 1)
+	}
+}
+
+func TestSave(t *testing.T) {
+	g, err := Example()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.EndTurn(); err != nil {
+		t.Fatal(err)
+	}
+	g.Regions["region7"].Castle.Damage(1)
+	g.Characters["jaime"].Capture("house1")
+	if err := g.Diplomacy.Propose(diplomats.NewProposal("house1", "house2", g.Characters["eddard"])); err != nil {
+		t.Fatal(err)
+	}
+	var saved bytes.Buffer
+	if err := g.Write(&saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(bytes.NewReader(saved.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again bytes.Buffer
+	if err := loaded.Write(&again); err != nil {
+		t.Fatal(err)
+	}
+	if saved.String() != again.String() {
+		t.Error("the loaded game should save the same", saved.String(), again.String())
+	}
+	army := loaded.Armies.Armies["army1"]
+	if loaded.Turn != 1 || army.Region != loaded.Regions[army.Region.Id] || army.Region.Edges["region2cost"].Dst != loaded.Regions["region2cost"] {
+		t.Error("the loaded game should be connected again")
+	}
+	if loaded.Diplomacy.RelationsTable["house1"]["house2"] != loaded.Diplomacy.RelationsTable["house2"]["house1"] {
+		t.Error("both houses should share their relation")
+	}
+	restored, err := g.Save().restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored.Armies.Armies["army1"].Size = 1
+	restored.Regions["region1"].Yield.Gold = 1000
+	restored.Houses["house3"].Liege = ""
+	if g.Armies.Armies["army1"].Size == 1 || g.Regions["region1"].Yield.Gold == 1000 || g.Houses["house3"].Liege == "" {
+		t.Error("the game should share nothing with its save")
+	}
+
+	var s Save
+	if err := json.Unmarshal(saved.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	s.Version = SAVE_VERSION + 1
+	if _, err := s.restore(); err == nil {
+		t.Error("saves of other versions should be refused")
+	}
+	s.Version = SAVE_VERSION
+	s.Armies.Armies[0].Region = "nowhere"
+	if _, err := s.restore(); err == nil {
+		t.Error("armies outside the regions should be refused")
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	if len(migrations) != SAVE_VERSION-1 {
+		t.Fatal("every version before the current one needs a migration")
+	}
+	// a game in the middle of a battle, saved by every version
+	for version := 1; version <= SAVE_VERSION; version++ {
+		data, err := ioutil.ReadFile(fmt.Sprintf("testdata/save_v%v.json", version))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := Load(bytes.NewReader(data))
+		if err != nil {
+			t.Error("version", version, err)
+			continue
+		}
+		army := g.Save().Armies.Armies[0]
+		if army.Id != "army1" || army.CombatState.String() != "COMBAT_PHASE1" || army.DefenseState != 1 {
+			t.Error("version", version, "army1 should still be in battle", army.CombatState, army.DefenseState)
+		}
+		if g.Turn != 1 || g.Characters["jaime"].Available() {
+			t.Error("version", version, "the game should be as it was saved")
+		}
+	}
+	_, err := Load(strings.NewReader(fmt.Sprintf(`{"Version": %v}`, SAVE_VERSION+1)))
+	if err == nil || !strings.Contains(err.Error(), "newer than this engine") {
+		t.Error("saves of newer versions should be refused", err)
+	}
+}
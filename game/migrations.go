@@ -0,0 +1,92 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+/*
+A migration upgrades a save from one version to the next. Saves of old versions
+can't be read into the structs of the engine, so migrations work on the decoded
+JSON, and must not rely on the constants of the engine, which may have changed
+since.
+*/
+type migration func(save map[string]interface{}) error
+
+// migrations[i] upgrades a save of version i+1, there is one for every version before SAVE_VERSION
+var migrations = []migration{
+	namedCombatPhases,
+}
+
+/*
+Migrate upgrades the JSON of a save of any earlier version to SAVE_VERSION.
+Saves written by a newer engine are refused, it can't know what they hold.
+*/
+func Migrate(data []byte) ([]byte, error) {
+	var save map[string]interface{}
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, err
+	}
+	number, ok := save["Version"].(float64)
+	version := int(number)
+	if !ok || float64(version) != number || version < 1 {
+		return nil, errors.New(fmt.Sprintf("save has no valid version: %v", save["Version"]))
+	}
+	if version > SAVE_VERSION {
+		return nil, errors.New(fmt.Sprintf("save version %v is newer than this engine, which reads up to version %v", version, SAVE_VERSION))
+	}
+	if version == SAVE_VERSION {
+		return data, nil
+	}
+	for ; version < SAVE_VERSION; version++ {
+		if err := migrations[version-1](save); err != nil {
+			return nil, errors.New(fmt.Sprintf("upgrading save from version %v: %v", version, err))
+		}
+	}
+	save["Version"] = SAVE_VERSION
+	return json.Marshal(save)
+}
+
+// armies and armies still mustering, as found in the save
+func savedArmies(save map[string]interface{}) (armies []map[string]interface{}, err error) {
+	manager, ok := save["Armies"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("save has no armies")
+	}
+	for _, key := range []string{"Armies", "Musters"} {
+		if manager[key] == nil {
+			continue
+		}
+		list, ok := manager[key].([]interface{})
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("save has invalid %v", key))
+		}
+		for _, item := range list {
+			army, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("save has invalid %v", key))
+			}
+			armies = append(armies, army)
+		}
+	}
+	return armies, nil
+}
+
+// version 1 saved combat phases by number, version 2 saves them by name
+func namedCombatPhases(save map[string]interface{}) error {
+	names := map[float64]string{0: "", 1: "COMBAT_PHASE1", 2: "COMBAT_PHASE2"}
+	armies, err := savedArmies(save)
+	if err != nil {
+		return err
+	}
+	for _, army := range armies {
+		phase, _ := army["CombatState"].(float64)
+		name, ok := names[phase]
+		if !ok {
+			return errors.New(fmt.Sprintf("army %v has unknown combat phase %v", army["Id"], phase))
+		}
+		army["CombatState"] = name
+	}
+	return nil
+}
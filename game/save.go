@@ -0,0 +1,152 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/pgruenbacher/got/armies"
+	"github.com/pgruenbacher/got/characters"
+	"github.com/pgruenbacher/got/diplomats"
+	"github.com/pgruenbacher/got/economy"
+	"github.com/pgruenbacher/got/families"
+	"github.com/pgruenbacher/got/regions"
+)
+
+// SAVE_VERSION is raised whenever the layout of a save changes, along with a
+// migration from the version before.
+const SAVE_VERSION = 2
+
+/*
+Save is the whole state of a game in the middle of play, laid out without the
+pointers between its parts so that it can be written to JSON. Loading it
+rebuilds the pointers.
+*/
+type Save struct {
+	Version    int
+	Turn       int
+	Houses     families.Houses
+	Regions    regions.RegionsState
+	Diplomacy  diplomats.TableState
+	Characters characters.Characters
+	Treasuries economy.Treasuries
+	Armies     armies.ManagerState
+}
+
+// Save lays out a copy of the game, so that nothing done to the save reaches the game.
+func (self *Game) Save() Save {
+	// the resolver isn't saved, so the seed of the copy is of no matter
+	c := self.Clone(0)
+	return Save{
+		Version:    SAVE_VERSION,
+		Turn:       c.Turn,
+		Houses:     c.Houses,
+		Regions:    c.Regions.Save(),
+		Diplomacy:  c.Diplomacy.Save(),
+		Characters: c.Characters,
+		Treasuries: c.Treasuries,
+		Armies:     c.Armies.Save(),
+	}
+}
+
+func (self *Game) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(self.Save())
+}
+
+// Load reads a game written by Write, of this or any earlier version.
+func Load(r io.Reader) (*Game, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = Migrate(data); err != nil {
+		return nil, err
+	}
+	var s Save
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return s.restore()
+}
+
+// restore rebuilds the game from the save, and checks that it holds together. The
+// game is built of the very houses, regions and armies of the save, so a save is
+// only restored once, as it is decoded.
+func (self Save) restore() (*Game, error) {
+	if self.Version != SAVE_VERSION {
+		return nil, errors.New(fmt.Sprintf("save version %v can't be loaded, expected version %v", self.Version, SAVE_VERSION))
+	}
+	g := &Game{
+		Turn:       self.Turn,
+		Houses:     self.Houses,
+		Characters: self.Characters,
+		Treasuries: self.Treasuries,
+	}
+	if g.Houses == nil {
+		return nil, errors.New("save has no houses")
+	}
+	for houseId, house := range g.Houses {
+		house.Id = houseId
+	}
+	for characterId, character := range g.Characters {
+		character.Id = characterId
+	}
+	if g.Treasuries == nil {
+		g.Treasuries = make(economy.Treasuries, len(g.Houses))
+	}
+	var err error
+	if g.Regions, err = self.Regions.Restore(); err != nil {
+		return nil, err
+	}
+	if err := g.Diplomacy.Restore(self.Diplomacy, g.Houses, g.Characters); err != nil {
+		return nil, err
+	}
+	if err := g.Armies.Restore(self.Armies, g.Regions, &g.Diplomacy, g.Characters, g.Treasuries); err != nil {
+		return nil, err
+	}
+	return g, g.Check()
+}
+
+// Check makes sure every part of the game refers to houses, regions and characters that exist.
+func (self *Game) Check() error {
+	for houseId, house := range self.Houses {
+		if !self.known(house.Liege) {
+			return errors.New(fmt.Sprintf("house %v liege %v does not exist", houseId, house.Liege))
+		}
+	}
+	for characterId, character := range self.Characters {
+		if !self.known(character.House) {
+			return errors.New(fmt.Sprintf("character %v house %v does not exist", characterId, character.House))
+		}
+	}
+	for houseId := range self.Treasuries {
+		if !self.known(houseId) {
+			return errors.New(fmt.Sprintf("treasury of house %v which does not exist", houseId))
+		}
+	}
+	if err := self.Regions.Check(); err != nil {
+		return err
+	}
+	for regionId, region := range self.Regions {
+		if !self.known(region.Owner) || !self.known(region.Controller) {
+			return errors.New(fmt.Sprintf("region %v is held by a house that does not exist", regionId))
+		}
+	}
+	if err := self.Diplomacy.Check(); err != nil {
+		return err
+	}
+	for id, army := range self.Armies.Armies {
+		if !self.known(army.House) || !self.known(army.LeviedBy) {
+			return errors.New(fmt.Sprintf("army %v serves a house that does not exist", id))
+		}
+	}
+	return self.Armies.Check()
+}
+
+// the armies and regions of eliminated houses become neutral, and belong to no house
+func (self *Game) known(houseId families.HouseId) bool {
+	_, ok := self.Houses[houseId]
+	return ok || houseId == ""
+}
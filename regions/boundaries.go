@@ -16,6 +16,20 @@ type Boundary interface {
 	borders() []RegionId
 	MovePenalty() int
 	AttackPenalty() float32
+	Kind() BoundaryKind
+}
+
+type BoundaryKind string
+
+const (
+	NONE  BoundaryKind = "NONE"
+	RIVER BoundaryKind = "RIVER"
+	WALL  BoundaryKind = "WALL"
+)
+
+// Destructible boundaries are worn down by the armies attacking across them.
+type Destructible interface {
+	Damage(n int)
 }
 
 type Boundaries interface {
@@ -45,6 +59,10 @@ func (self River) AttackPenalty() float32 {
 	return self.AttackingPenalty
 }
 
+func (River) Kind() BoundaryKind {
+	return RIVER
+}
+
 type Rivers map[string]*River
 
 type Wall struct {
@@ -52,6 +70,9 @@ type Wall struct {
 	Borders          []RegionId
 	MovementPenalty  int     `toml:"movement_penalty"`
 	AttackingPenalty float32 `toml:"attack_penalty"`
+	// integrity of the wall, the attack penalty weakens as it is damaged
+	Strength int `toml:"strength"`
+	Damaged  int
 }
 
 func (r Wall) borders() []RegionId {
@@ -63,7 +84,21 @@ func (r Wall) MovePenalty() int {
 }
 
 func (self Wall) AttackPenalty() float32 {
-	return self.AttackingPenalty
+	if self.Strength == 0 {
+		return self.AttackingPenalty
+	}
+	return self.AttackingPenalty * float32(self.Strength-self.Damaged) / float32(self.Strength)
+}
+
+func (Wall) Kind() BoundaryKind {
+	return WALL
+}
+
+func (self *Wall) Damage(n int) {
+	self.Damaged = self.Damaged + n
+	if self.Damaged > self.Strength {
+		self.Damaged = self.Strength
+	}
 }
 
 type Walls map[string]*Wall
@@ -81,6 +116,10 @@ func (NoBoundary) borders() []RegionId {
 	return nil
 }
 
+func (NoBoundary) Kind() BoundaryKind {
+	return NONE
+}
+
 func (self Regions) IncorporateBoundary(b Boundary) error {
 	if len(b.borders())%2 != 0 {
 		return OddBorderNumber
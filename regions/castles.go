@@ -0,0 +1,52 @@
+package regions
+
+import "github.com/pgruenbacher/got/families"
+
+/*
+Castles fortify a region. While the garrison holds, enemies can't occupy the
+region, and must besiege the castle until it is starved out or assaulted.
+*/
+type Castle struct {
+	Name     string
+	Garrison int `toml:"garrison" validate:"min=0"`
+	// defense bonus of the garrison while the walls are intact
+	Defense float32 `toml:"defense"`
+	// integrity of the walls, assaults wear them down
+	Walls int `toml:"walls" validate:"min=0"`
+	// turns the garrison can hold out once besieged
+	Provisions int `toml:"provisions" validate:"min=0"`
+	// house besieging the castle, empty if not under siege
+	Besieger   families.HouseId
+	SiegeTurns int
+	maxWalls   int
+}
+
+// Holds is true while there is a garrison left to defend the castle.
+func (self *Castle) Holds() bool {
+	return self != nil && self.Garrison > 0
+}
+
+func (self *Castle) Besieged() bool {
+	return self != nil && self.Besieger != ""
+}
+
+// DefenseBonus weakens as the walls are damaged.
+func (self *Castle) DefenseBonus() float32 {
+	if self.maxWalls == 0 {
+		return self.Defense
+	}
+	return self.Defense * float32(self.Walls) / float32(self.maxWalls)
+}
+
+func (self *Castle) Damage(n int) {
+	self.Walls = self.Walls - n
+	if self.Walls < 0 {
+		self.Walls = 0
+	}
+}
+
+func (self *Castle) init() {
+	if self.maxWalls < self.Walls {
+		self.maxWalls = self.Walls
+	}
+}
@@ -0,0 +1,59 @@
+package regions
+
+/*
+Clone copies the regions along with their castles, edges and boundaries, so that
+the copy may be changed without touching the original. Boundaries shared by the
+two edges of a border stay shared in the copy.
+*/
+func (self Regions) Clone() Regions {
+	clone := make(Regions, len(self))
+	for id, region := range self {
+		r := *region
+		r.Neighbors = append([]RegionId(nil), region.Neighbors...)
+		if region.Castle != nil {
+			castle := *region.Castle
+			r.Castle = &castle
+		}
+		clone[id] = &r
+	}
+	boundaries := make(map[Boundary]Boundary)
+	for id, region := range self {
+		if region.Edges == nil {
+			continue
+		}
+		r := clone[id]
+		r.Edges = make(map[RegionId]*Edge, len(region.Edges))
+		for dst, edge := range region.Edges {
+			r.Edges[dst] = &Edge{
+				Src:      r,
+				Dst:      clone[edge.Dst.Id],
+				Boundary: cloneBoundary(edge.Boundary, boundaries),
+			}
+		}
+	}
+	return clone
+}
+
+func cloneBoundary(b Boundary, cloned map[Boundary]Boundary) Boundary {
+	if c, ok := cloned[b]; ok {
+		return c
+	}
+	var c Boundary
+	switch t := b.(type) {
+	case *River:
+		river := *t
+		river.Borders = append([]RegionId(nil), t.Borders...)
+		c = &river
+	case *Wall:
+		wall := *t
+		wall.Borders = append([]RegionId(nil), t.Borders...)
+		c = &wall
+	case *NoBoundary:
+		c = new(NoBoundary)
+	default:
+		// boundaries held by value can't be changed through the edge
+		c = b
+	}
+	cloned[b] = c
+	return c
+}
@@ -3,6 +3,10 @@ package regions
 import (
 	"errors"
 	"fmt"
+
+	"github.com/pgruenbacher/got/economy"
+	"github.com/pgruenbacher/got/events"
+	"github.com/pgruenbacher/got/families"
 )
 
 type Regions map[RegionId]*Region
@@ -26,11 +30,21 @@ type Region struct {
 	// Overcapacity will lead to penalties, especially if supply route is cutof
 	Capacity int
 	// Edges go from this region to others.
-	Edges map[RegionId]*Edge
+	Edges map[RegionId]*Edge `json:"-"`
 	// terrain type
 	Terrain Terrain
 	// Capfacity
 	Neighbors []RegionId
+	// Owner is the house holding the region, empty if unclaimed.
+	Owner families.HouseId
+	// Controller is the house whose armies hold the region, which may be an occupier.
+	Controller families.HouseId
+	// number of turns the controller has occupied the region of another owner
+	Occupation int
+	// Castle fortifying the region, nil if there is none.
+	Castle *Castle `toml:"castle"`
+	// resources the region yields to its owner every turn
+	Yield economy.Resources `toml:"yield"`
 	// Rivers    []RegionId
 	// Walls     []RegionId
 }
@@ -54,10 +68,111 @@ type Edge struct {
 	Boundary Boundary
 }
 
+type OwnershipContext string
+
+const (
+	// an enemy army holds the region unopposed and takes control
+	OCCUPIED OwnershipContext = "OCCUPIED"
+	// the occupier held the region long enough to become the owner
+	CONQUERED OwnershipContext = "CONQUERED"
+	// the owner retakes control from the occupier
+	LIBERATED OwnershipContext = "LIBERATED"
+	// the region was handed over, or became unclaimed
+	TRANSFERRED OwnershipContext = "TRANSFERRED"
+)
+
+// the region changed controller or owner, To is empty if it became unclaimed
+type OwnerEvent struct {
+	events.Event
+	RegionId RegionId
+	From     families.HouseId
+	To       families.HouseId
+	Ctx      OwnershipContext
+}
+
+func (self Regions) OwnedBy(houseId families.HouseId) (regions []*Region) {
+	for _, region := range self {
+		if region.Owner == houseId {
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
+
+func (self Regions) ControlledBy(houseId families.HouseId) (regions []*Region) {
+	for _, region := range self {
+		if region.Controller == houseId {
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
+
+// Transfer hands every region of one house over to another.
+func (self Regions) Transfer(from, to families.HouseId) (events []OwnerEvent) {
+	for _, region := range self {
+		if region.Owner == from {
+			region.Owner = to
+			events = append(events, newOwnerEvent(region.Id, from, to, TRANSFERRED))
+		}
+		if region.Controller == from {
+			region.Controller = to
+			region.Occupation = 0
+		}
+	}
+	return events
+}
+
+// Occupy records another turn the house holds the region unopposed. The house
+// takes control at once, and ownership once it has held the region for the given turns.
+func (self *Region) Occupy(houseId families.HouseId, turns int) (events []OwnerEvent) {
+	if self.Controller != houseId {
+		events = append(events, newOwnerEvent(self.Id, self.Controller, houseId, OCCUPIED))
+		self.Controller = houseId
+		self.Occupation = 0
+	}
+	if self.Owner == houseId {
+		return events
+	}
+	self.Occupation++
+	if self.Occupation >= turns {
+		events = append(events, newOwnerEvent(self.Id, self.Owner, houseId, CONQUERED))
+		self.Owner = houseId
+		self.Occupation = 0
+	}
+	return events
+}
+
+// Liberate returns control of an occupied region to its owner.
+func (self *Region) Liberate() (events []OwnerEvent) {
+	if self.Controller != self.Owner {
+		events = append(events, newOwnerEvent(self.Id, self.Controller, self.Owner, LIBERATED))
+		self.Controller = self.Owner
+	}
+	self.Occupation = 0
+	return events
+}
+
+func newOwnerEvent(id RegionId, from, to families.HouseId, ctx OwnershipContext) OwnerEvent {
+	return OwnerEvent{
+		Event:    events.NewEvent(),
+		RegionId: id,
+		From:     from,
+		To:       to,
+		Ctx:      ctx,
+	}
+}
+
 func (self Regions) initializeAll() bool {
 	for regionId, region := range self {
 		region.Edges = make(map[RegionId]*Edge, len(region.Neighbors))
 		region.Id = regionId
+		if region.Controller == "" {
+			region.Controller = region.Owner
+		}
+		if region.Castle != nil {
+			region.Castle.init()
+		}
 	}
 	return true
 }
@@ -128,44 +243,63 @@ func checkNeighbors(a RegionId, list []RegionId) bool {
 
 var ExampleRegions string = `
     [region1]
+    owner = "house1"
+    yield = {gold = 3, food = 4, manpower = 5}
     size = 3
     neighbors = ["region2","region4","region2cost"]
 
 
     [region2]
+    owner = "house3"
     size = 3
     terrain = "PLAIN"
     neighbors = ["region1","region3"]
 
     [region2cost]
+    owner = "house1"
     terrain="MOUNTAIN"
     neighbors=["region1","region3cost"]
 
     [region3cost]
+    owner = "house2"
     terrain="MOUNTAIN"
     neighbors=["region2cost","region6"]
 
 
     [region3]
+    owner = "house2"
+    yield = {gold = 4, food = 2, manpower = 3}
     size = 3
     terrain="PLAIN"
     neighbors = ["region2","region7"]
 
     [region7]
+    owner = "house2"
+    yield = {gold = 6, food = 2, manpower = 4}
     size = 3 
     neighbors =["region3","region6"]
 
+    [region7.castle]
+    name = "casterly rock"
+    garrison = 20
+    defense = 0.5
+    walls = 4
+    provisions = 2
+
     [region4]
+    owner = "house4"
     size = 3
     terrain = "PLAIN"
     neighbors = ["region1","region5"]
 
 
     [region5]
+    owner = "house5"
     size = 3
     neighbors = ["region4"]
 
     [region6]
+    owner = "house2"
     size = 3
     neighbors = ["region7","region3cost"]
     `
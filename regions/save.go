@@ -0,0 +1,139 @@
+package regions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/pgruenbacher/got/families"
+)
+
+/*
+RegionsState is the regions laid out for saving. The edges are left out, they
+are rebuilt from the neighbors, and the boundaries are kept apart to be laid
+across them again.
+*/
+type RegionsState struct {
+	Regions []*Region
+	Rivers  []*River
+	Walls   []*Wall
+}
+
+// castles are saved with their undamaged walls, which can't be told from the damaged ones
+type castle Castle
+
+type savedCastle struct {
+	*castle
+	MaxWalls int
+}
+
+func (self *Castle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(savedCastle{(*castle)(self), self.maxWalls})
+}
+
+func (self *Castle) UnmarshalJSON(data []byte) error {
+	saved := savedCastle{castle: (*castle)(self)}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	self.maxWalls = saved.MaxWalls
+	return nil
+}
+
+func (self Regions) Save() (s RegionsState) {
+	seen := make(map[Boundary]bool)
+	for _, id := range self.ids() {
+		region := self[id]
+		s.Regions = append(s.Regions, region)
+		for _, dst := range region.neighborIds() {
+			boundary := region.Edges[dst].Boundary
+			if seen[boundary] {
+				continue
+			}
+			seen[boundary] = true
+			switch t := boundary.(type) {
+			case *River:
+				s.Rivers = append(s.Rivers, t)
+			case *Wall:
+				s.Walls = append(s.Walls, t)
+			}
+		}
+	}
+	return s
+}
+
+// Restore connects the saved regions again, and lays the boundaries across their edges.
+func (self RegionsState) Restore() (Regions, error) {
+	r := make(Regions, len(self.Regions))
+	controllers := make(map[RegionId]families.HouseId, len(self.Regions))
+	for _, region := range self.Regions {
+		if _, ok := r[region.Id]; ok {
+			return nil, errors.New(fmt.Sprintf("region %v saved twice", region.Id))
+		}
+		r[region.Id] = region
+		controllers[region.Id] = region.Controller
+	}
+	if err := r.ConnectAll(); err != nil {
+		return nil, err
+	}
+	// connecting hands unclaimed regions to their owners, which a saved game has already done
+	for id, region := range r {
+		region.Controller = controllers[id]
+	}
+	for _, river := range self.Rivers {
+		if err := r.IncorporateBoundary(river); err != nil {
+			return nil, err
+		}
+	}
+	for _, wall := range self.Walls {
+		if err := r.IncorporateBoundary(wall); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Check makes sure every edge leads between the regions it belongs to, both ways.
+func (self Regions) Check() error {
+	for id, region := range self {
+		if region.Id != id {
+			return errors.New(fmt.Sprintf("region %v saved as %v", region.Id, id))
+		}
+		for dst, edge := range region.Edges {
+			if edge.Src != region || edge.Dst != self[dst] {
+				return errors.New(fmt.Sprintf("edge %v to %v leads elsewhere", id, dst))
+			}
+			back, ok := edge.Dst.Edges[id]
+			if !ok || back.Dst != region || back.Boundary.Kind() != edge.Boundary.Kind() {
+				return errors.New(fmt.Sprintf("edge %v to %v has no matching edge back", id, dst))
+			}
+		}
+	}
+	return nil
+}
+
+// regions are saved in order, so that the same game always saves the same
+func (self Regions) ids() []RegionId {
+	var ids []string
+	for id := range self {
+		ids = append(ids, string(id))
+	}
+	return sorted(ids)
+}
+
+func (self *Region) neighborIds() []RegionId {
+	var ids []string
+	for id := range self.Edges {
+		ids = append(ids, string(id))
+	}
+	return sorted(ids)
+}
+
+func sorted(s []string) (ids []RegionId) {
+	sort.Strings(s)
+	for _, id := range s {
+		ids = append(ids, RegionId(id))
+	}
+	return ids
+}